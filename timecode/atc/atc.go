@@ -0,0 +1,176 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package atc implements SMPTE ST 12-2 ancillary timecode (ATC) packets as
+// carried in SDI ancillary data (formerly RP 188).
+//
+// An ATC packet has DID 0x60, SDID 0x60 and 16 user data words (UDW). Each
+// 10 bit word carries one nibble of the LTC data word and one distributed
+// binary bit (DBB):
+//
+//	b0-b2  reserved, 0
+//	b3     distributed binary bit
+//	b4-b7  timecode or user bits nibble in LTC order
+//	b8     even parity of b0-b7
+//	b9     inverse of b8
+//
+// The DBB of words 1-8 form DBB1 (payload type), the DBB of words 9-16
+// form DBB2 (VITC line select and status flags), each LSB first.
+package atc
+
+import (
+	"errors"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+const (
+	DID  = 0x60
+	SDID = 0x60
+	// Words is the number of user data words in an ATC packet.
+	Words = 16
+)
+
+// ATC payload types in DBB1.
+const (
+	TypeLTC   uint8 = 0x00
+	TypeVITC1 uint8 = 0x01
+	TypeVITC2 uint8 = 0x02
+)
+
+var (
+	ErrLength   = errors.New("atc: invalid packet length")
+	ErrParity   = errors.New("atc: parity error")
+	ErrPacket   = errors.New("atc: not an ATC packet")
+	ErrChecksum = errors.New("atc: checksum mismatch")
+)
+
+// Packet is the contents of an ATC packet.
+type Packet struct {
+	Timecode timecode.Timecode
+	UserBits timecode.UserBits
+	Flags    timecode.SMPTEFlags
+	// DBB1 is the payload type, see TypeLTC, TypeVITC1 and TypeVITC2.
+	DBB1 uint8
+	// DBB2 holds the VITC line select in bits 0-4 and status flags.
+	DBB2 uint8
+}
+
+// word returns the 10 bit ancillary data word for 8 bit value v with
+// parity bits b8 and b9.
+func word(v uint8) uint16 {
+	w := uint16(v)
+	p := uint16(0)
+	for ; v > 0; v &= v - 1 {
+		p ^= 1
+	}
+	return w | p<<8 | (p^1)<<9
+}
+
+// checkWord returns the 8 bit value of 10 bit word w after checking
+// its parity bits.
+func checkWord(w uint16) (uint8, error) {
+	if word(uint8(w)) != w&0x3FF {
+		return 0, ErrParity
+	}
+	return uint8(w), nil
+}
+
+// UDW returns the packet's 16 user data words.
+func (p Packet) UDW() [Words]uint16 {
+	var udw [Words]uint16
+	tc, bits := p.Timecode.SMPTEWithFlags(p.UserBits, p.Flags)
+	data := timecode.InterleaveSMPTE(tc, bits)
+	dbb := uint16(p.DBB1) | uint16(p.DBB2)<<8
+	for i := range udw {
+		v := uint8(data>>(4*uint(i))&0x0F) << 4
+		v |= uint8(dbb>>uint(i)&1) << 3
+		udw[i] = word(v)
+	}
+	return udw
+}
+
+// DecodeUDW returns the packet contents from user data words udw at rate
+// r after checking parity.
+func DecodeUDW(udw []uint16, r timecode.Rate) (Packet, error) {
+	if len(udw) != Words {
+		return Packet{}, ErrLength
+	}
+	var (
+		data uint64
+		dbb  uint16
+	)
+	for i, w := range udw {
+		v, err := checkWord(w)
+		if err != nil {
+			return Packet{}, err
+		}
+		data |= uint64(v>>4) << (4 * uint(i))
+		dbb |= uint16(v>>3&1) << uint(i)
+	}
+	tc, bits := timecode.DeinterleaveSMPTE(data)
+	t, u, f := timecode.FromSMPTEWithFlags(tc, bits, r)
+	return Packet{
+		Timecode: t,
+		UserBits: u,
+		Flags:    f,
+		DBB1:     uint8(dbb),
+		DBB2:     uint8(dbb >> 8),
+	}, nil
+}
+
+// ANC returns the ancillary data packet words DID, SDID, data count, the
+// user data words and checksum. The ancillary data flag is not included.
+func (p Packet) ANC() []uint16 {
+	words := make([]uint16, 0, Words+4)
+	words = append(words, word(DID), word(SDID), word(Words))
+	udw := p.UDW()
+	words = append(words, udw[:]...)
+	return append(words, checksum(words))
+}
+
+// DecodeANC returns the packet contents from ancillary data packet words
+// starting at the DID at rate r. It checks DID, SDID, data count, parity
+// and checksum.
+func DecodeANC(words []uint16, r timecode.Rate) (Packet, error) {
+	if len(words) < 3 {
+		return Packet{}, ErrLength
+	}
+	if words[0]&0xFF != DID || words[1]&0xFF != SDID {
+		return Packet{}, ErrPacket
+	}
+	if words[2]&0xFF != Words || len(words) != Words+4 {
+		return Packet{}, ErrLength
+	}
+	for _, w := range words[:3] {
+		if _, err := checkWord(w); err != nil {
+			return Packet{}, err
+		}
+	}
+	if checksum(words[:Words+3]) != words[Words+3]&0x3FF {
+		return Packet{}, ErrChecksum
+	}
+	return DecodeUDW(words[3:Words+3], r)
+}
+
+// checksum returns the ancillary data checksum word over words, which is
+// the 9 bit sum of bits b0-b8 with b9 set to the inverse of b8.
+func checksum(words []uint16) uint16 {
+	var sum uint16
+	for _, w := range words {
+		sum += w & 0x1FF
+	}
+	sum &= 0x1FF
+	return sum | (^sum&0x100)<<1
+}
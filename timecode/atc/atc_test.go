@@ -0,0 +1,137 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package atc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+type ATCTestcase struct {
+	Name     string
+	Timecode string
+	Rate     timecode.Rate
+	UserBits timecode.UserBits
+	Flags    timecode.SMPTEFlags
+	DBB1     uint8
+	DBB2     uint8
+}
+
+var ATCTestcases []ATCTestcase = []ATCTestcase{
+	{"25_LTC", "10:00:00:00", timecode.Rate25, 0, timecode.SMPTEFlags{}, TypeLTC, 0},
+	{"29_97DF_VITC1", "01:59:59;29", timecode.Rate30DF, timecode.CharUserBits("SDI"), timecode.SMPTEFlags{BGF: timecode.UserBitsChars}, TypeVITC1, 0x0E},
+	{"29_97NDF_LTC", "00:01:00:00", timecode.Rate30DF, 0, timecode.SMPTEFlags{}, TypeLTC, 0},
+	{"30_VITC2", "23:59:59:29", timecode.Rate30, 0xFFFFFFFF, timecode.SMPTEFlags{FieldMark: true, ColorFrame: true}, TypeVITC2, 0xCE},
+	{"50_pair", "12:00:00:49", timecode.Rate50, 0x12345678, timecode.SMPTEFlags{}, TypeLTC, 0x80},
+	{"59_94DF", "00:09:59;59", timecode.Rate60DF, 0, timecode.SMPTEFlags{}, TypeLTC, 0},
+}
+
+func (v ATCTestcase) Packet(t *testing.T) Packet {
+	tc, err := timecode.Parse(v.Timecode + "@" + v.Rate.FloatString())
+	if err != nil {
+		t.Fatalf("[Case #%s] Parse failed: %v", v.Name, err)
+	}
+	return Packet{tc, v.UserBits, v.Flags, v.DBB1, v.DBB2}
+}
+
+func TestANC(t *testing.T) {
+	p := Packet{Timecode: timecode.New(10*time.Hour, timecode.Rate25)}
+	expected := []uint16{0x260, 0x260, 0x110}
+	for i := 0; i < Words; i++ {
+		if i == 14 {
+			// hours tens
+			expected = append(expected, 0x110)
+		} else {
+			expected = append(expected, 0x200)
+		}
+	}
+	expected = append(expected, 0x2E0)
+	if words := p.ANC(); !reflect.DeepEqual(words, expected) {
+		t.Errorf("Wrong ANC packet: expected=%03x got=%03x", expected, words)
+	}
+}
+
+func TestUDW(t *testing.T) {
+	for _, v := range ATCTestcases {
+		p := v.Packet(t)
+		udw := p.UDW()
+		for i, w := range udw {
+			if w&0x7 != 0 {
+				t.Errorf("[Case #%s] Reserved bits set in word %d: %03x", v.Name, i+1, w)
+			}
+		}
+		x, err := DecodeUDW(udw[:], v.Rate)
+		if err != nil {
+			t.Errorf("[Case #%s] DecodeUDW failed: %v", v.Name, err)
+		}
+		if x.Timecode != p.Timecode || x.UserBits != p.UserBits || x.DBB1 != p.DBB1 || x.DBB2 != p.DBB2 {
+			t.Errorf("[Case #%s] Wrong packet: expected=%s/%08x/%02x/%02x got=%s/%08x/%02x/%02x", v.Name,
+				p.Timecode, uint32(p.UserBits), p.DBB1, p.DBB2,
+				x.Timecode, uint32(x.UserBits), x.DBB1, x.DBB2)
+		}
+		tc, _ := p.Timecode.SMPTEWithFlags(p.UserBits, p.Flags)
+		if x.Flags != timecode.FlagsFromSMPTE(tc, v.Rate) {
+			t.Errorf("[Case #%s] Wrong flags: expected=%+v got=%+v", v.Name, p.Flags, x.Flags)
+		}
+		y, err := DecodeANC(p.ANC(), v.Rate)
+		if err != nil {
+			t.Errorf("[Case #%s] DecodeANC failed: %v", v.Name, err)
+		}
+		if y != x {
+			t.Errorf("[Case #%s] Wrong ANC packet: expected=%+v got=%+v", v.Name, x, y)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	p := Packet{Timecode: timecode.New(time.Hour, timecode.Rate25), DBB2: 0x55}
+	udw := p.UDW()
+	if _, err := DecodeUDW(udw[:15], timecode.Rate25); err != ErrLength {
+		t.Errorf("Expected length error, got %v", err)
+	}
+	for i := range udw {
+		for b := uint(0); b < 10; b++ {
+			x := udw
+			x[i] ^= 1 << b
+			if _, err := DecodeUDW(x[:], timecode.Rate25); err != ErrParity {
+				t.Errorf("Word %d bit %d flipped: expected parity error, got %v", i, b, err)
+			}
+		}
+	}
+	words := p.ANC()
+	for i, v := range []struct {
+		Index int
+		Value uint16
+		Err   error
+	}{
+		{0, 0x241, ErrPacket},
+		{1, 0x161, ErrPacket},
+		{2, 0x20F, ErrLength},
+		{19, words[19] ^ 1, ErrChecksum},
+		{2, words[2] ^ 0x300, ErrParity},
+	} {
+		x := append([]uint16{}, words...)
+		x[v.Index] = v.Value
+		if _, err := DecodeANC(x, timecode.Rate25); err != v.Err {
+			t.Errorf("[Case #%d] Expected error %v, got %v", i, v.Err, err)
+		}
+	}
+	if _, err := DecodeANC(words[:19], timecode.Rate25); err != ErrLength {
+		t.Errorf("Expected length error, got %v", err)
+	}
+}
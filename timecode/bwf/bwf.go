@@ -0,0 +1,231 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package bwf reads and writes the time reference of Broadcast WAV files.
+//
+// EBU Tech 3285 defines the 'bext' chunk with a 64 bit TimeReference field
+// that holds the position of the file's first sample as number of samples
+// since midnight. The field is stored as two little-endian 32 bit words at
+// offset 338 of the chunk data, low word first. RF64 and BW64 files are
+// supported as well.
+package bwf
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+// TimeReferenceOffset is the offset of the TimeReference field in the
+// 'bext' chunk data.
+const TimeReferenceOffset = 338
+
+// minimum 'bext' chunk size that includes the TimeReference field
+const bextMinSize = TimeReferenceOffset + 8
+
+var (
+	ErrFormat = errors.New("bwf: invalid wave file")
+	ErrBext   = errors.New("bwf: missing bext chunk")
+	ErrSize   = errors.New("bwf: bext chunk too short")
+)
+
+// ParseTimeReference returns the TimeReference field from 'bext' chunk
+// data.
+func ParseTimeReference(bext []byte) (uint64, error) {
+	if len(bext) < bextMinSize {
+		return 0, ErrSize
+	}
+	return binary.LittleEndian.Uint64(bext[TimeReferenceOffset:]), nil
+}
+
+// PutTimeReference sets the TimeReference field in 'bext' chunk data to n.
+func PutTimeReference(bext []byte, n uint64) error {
+	if len(bext) < bextMinSize {
+		return ErrSize
+	}
+	binary.LittleEndian.PutUint64(bext[TimeReferenceOffset:], n)
+	return nil
+}
+
+// ReadTimeReference reads a wave file from r and returns the TimeReference
+// of its 'bext' chunk together with the sample rate from its 'fmt ' chunk.
+// Reading stops once both chunks are found.
+func ReadTimeReference(r io.Reader) (uint64, int, error) {
+	var (
+		ref        uint64
+		sampleRate int
+		hasRef     bool
+	)
+	err := walk(r, func(id string, size int64, data io.Reader) (bool, error) {
+		switch id {
+		case "fmt ":
+			var b [8]byte
+			if size < int64(len(b)) {
+				return true, ErrFormat
+			}
+			if _, err := io.ReadFull(data, b[:]); err != nil {
+				return true, ErrFormat
+			}
+			sampleRate = int(binary.LittleEndian.Uint32(b[4:]))
+		case "bext":
+			if size < bextMinSize {
+				return true, ErrSize
+			}
+			b := make([]byte, bextMinSize)
+			if _, err := io.ReadFull(data, b); err != nil {
+				return true, ErrFormat
+			}
+			ref, _ = ParseTimeReference(b)
+			hasRef = true
+		}
+		return hasRef && sampleRate > 0, nil
+	})
+	switch {
+	case err != nil:
+		return 0, 0, err
+	case !hasRef:
+		return 0, 0, ErrBext
+	case sampleRate <= 0:
+		return 0, 0, ErrFormat
+	}
+	return ref, sampleRate, nil
+}
+
+// WriteTimeReference sets the TimeReference of the 'bext' chunk in wave
+// file f to n. The file is modified in place, other chunks are left
+// untouched.
+func WriteTimeReference(f io.ReadWriteSeeker, n uint64) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	found := false
+	err := walk(f, func(id string, size int64, data io.Reader) (bool, error) {
+		if id != "bext" {
+			return false, nil
+		}
+		if size < bextMinSize {
+			return true, ErrSize
+		}
+		if _, err := f.Seek(TimeReferenceOffset, io.SeekCurrent); err != nil {
+			return true, err
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], n)
+		if _, err := f.Write(b[:]); err != nil {
+			return true, err
+		}
+		found = true
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrBext
+	}
+	return nil
+}
+
+// Timecode reads a wave file from r and returns the timecode of its first
+// sample at video rate vr.
+func Timecode(r io.Reader, vr timecode.Rate) (timecode.Timecode, error) {
+	n, sampleRate, err := ReadTimeReference(r)
+	if err != nil {
+		return timecode.Invalid, err
+	}
+	return timecode.FromSamples(n, sampleRate, vr), nil
+}
+
+// SetTimecode sets the TimeReference of wave file f to the first sample of
+// timecode t at sampleRate. Negative timecodes are wrapped at 24h.
+func SetTimecode(f io.ReadWriteSeeker, t timecode.Timecode, sampleRate int) error {
+	t, _ = t.Wrap()
+	return WriteTimeReference(f, uint64(t.Samples(sampleRate)))
+}
+
+// walk calls fn for each chunk of the wave file read from r with the chunk
+// id, the chunk size and a reader limited to the chunk data. Unread chunk
+// data is skipped, using Seek when r is an io.Seeker. Walking stops when fn
+// returns true or an error, or at the end of the file.
+func walk(r io.Reader, fn func(id string, size int64, data io.Reader) (bool, error)) error {
+	var hdr [12]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return ErrFormat
+	}
+	switch string(hdr[:4]) {
+	case "RIFF", "RF64", "BW64":
+	default:
+		return ErrFormat
+	}
+	if string(hdr[8:]) != "WAVE" {
+		return ErrFormat
+	}
+	// 64 bit data chunk size from an RF64 'ds64' chunk
+	var dataSize int64 = -1
+	for {
+		var ch [8]byte
+		if _, err := io.ReadFull(r, ch[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return ErrFormat
+		}
+		id := string(ch[:4])
+		size := int64(binary.LittleEndian.Uint32(ch[4:]))
+		if id == "data" && size == math.MaxUint32 && dataSize >= 0 {
+			size = dataSize
+		}
+		data := &io.LimitedReader{R: r, N: size}
+		if id == "ds64" {
+			var b [16]byte
+			if _, err := io.ReadFull(data, b[:]); err != nil {
+				return ErrFormat
+			}
+			dataSize = int64(binary.LittleEndian.Uint64(b[8:]))
+			if dataSize < 0 {
+				return ErrFormat
+			}
+		} else {
+			done, err := fn(id, size, data)
+			if done || err != nil {
+				return err
+			}
+		}
+		// chunks are padded to an even size
+		if err := skip(r, data.N+size&1); err != nil {
+			return err
+		}
+	}
+}
+
+func skip(r io.Reader, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(n, io.SeekCurrent)
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, r, n); err != nil {
+		if err == io.EOF {
+			// the final pad byte is often missing
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package bwf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+func chunk(id string, data []byte) []byte {
+	b := make([]byte, 8, 8+len(data)+1)
+	copy(b, id)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(data)))
+	b = append(b, data...)
+	if len(data)&1 > 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func fmtChunk(sampleRate int) []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint16(b, 1)
+	binary.LittleEndian.PutUint16(b[2:], 2)
+	binary.LittleEndian.PutUint32(b[4:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(b[8:], uint32(sampleRate*4))
+	binary.LittleEndian.PutUint16(b[12:], 4)
+	binary.LittleEndian.PutUint16(b[14:], 16)
+	return chunk("fmt ", b)
+}
+
+func bextChunk(ref uint64) []byte {
+	b := make([]byte, 602)
+	copy(b, "description")
+	PutTimeReference(b, ref)
+	return chunk("bext", b)
+}
+
+func wave(form string, chunks ...[]byte) []byte {
+	b := []byte(form + "\x00\x00\x00\x00WAVE")
+	for _, c := range chunks {
+		b = append(b, c...)
+	}
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)-8))
+	return b
+}
+
+// reader hides the Seek method of the wrapped reader
+type reader struct {
+	io.Reader
+}
+
+func TestTimeReference(t *testing.T) {
+	b := make([]byte, bextMinSize)
+	if err := PutTimeReference(b, 0x0123456789ABCDEF); err != nil {
+		t.Errorf("PutTimeReference failed: %v", err)
+	}
+	if !bytes.Equal(b[TimeReferenceOffset:], []byte{0xEF, 0xCD, 0xAB, 0x89, 0x67, 0x45, 0x23, 0x01}) {
+		t.Errorf("Wrong time reference bytes: % x", b[TimeReferenceOffset:])
+	}
+	if n, err := ParseTimeReference(b); err != nil || n != 0x0123456789ABCDEF {
+		t.Errorf("Wrong time reference: %x (%v)", n, err)
+	}
+	if _, err := ParseTimeReference(b[:bextMinSize-1]); err != ErrSize {
+		t.Errorf("Expected size error, got %v", err)
+	}
+	if err := PutTimeReference(b[:bextMinSize-1], 0); err != ErrSize {
+		t.Errorf("Expected size error, got %v", err)
+	}
+}
+
+func TestReadTimeReference(t *testing.T) {
+	// 01:00:00;00 at 29.97DF
+	const ref = 172799828
+	for i, b := range [][]byte{
+		wave("RIFF", fmtChunk(48000), bextChunk(ref), chunk("data", make([]byte, 1001))),
+		wave("RIFF", chunk("JUNK", make([]byte, 27)), bextChunk(ref), chunk("data", make([]byte, 4)), fmtChunk(48000)),
+		wave("RF64", chunk("ds64", make([]byte, 28)), fmtChunk(48000), bextChunk(ref)),
+	} {
+		for _, r := range []io.Reader{bytes.NewReader(b), reader{bytes.NewReader(b)}} {
+			n, sampleRate, err := ReadTimeReference(r)
+			if err != nil {
+				t.Errorf("[Case #%d] ReadTimeReference failed: %v", i, err)
+			}
+			if n != ref || sampleRate != 48000 {
+				t.Errorf("[Case #%d] Wrong time reference: expected=%d/48000 got=%d/%d", i, ref, n, sampleRate)
+			}
+		}
+		tc, err := Timecode(bytes.NewReader(b), timecode.Rate30DF)
+		if err != nil {
+			t.Errorf("[Case #%d] Timecode failed: %v", i, err)
+		}
+		if s := tc.StringWithRate(); s != "01:00:00;00@29.970" {
+			t.Errorf("[Case #%d] Wrong timecode: expected=01:00:00;00@29.970 got=%s", i, s)
+		}
+	}
+}
+
+func TestReadTimeReferenceErrors(t *testing.T) {
+	for i, v := range []struct {
+		Data []byte
+		Err  error
+	}{
+		{[]byte("RIFF"), ErrFormat},
+		{wave("RIFX", fmtChunk(48000), bextChunk(0)), ErrFormat},
+		{wave("RIFF", fmtChunk(48000)), ErrBext},
+		{wave("RIFF", bextChunk(0)), ErrFormat},
+		{wave("RIFF", fmtChunk(48000), chunk("bext", make([]byte, 300))), ErrSize},
+		{wave("RIFF", fmtChunk(48000), bextChunk(0))[:380], ErrFormat},
+	} {
+		if _, _, err := ReadTimeReference(bytes.NewReader(v.Data)); err != v.Err {
+			t.Errorf("[Case #%d] Expected error %v, got %v", i, v.Err, err)
+		}
+	}
+}
+
+func TestWriteTimeReference(t *testing.T) {
+	b := wave("RIFF", fmtChunk(48000), chunk("JUNK", make([]byte, 3)), bextChunk(0), chunk("data", make([]byte, 64)))
+	name := filepath.Join(t.TempDir(), "test.wav")
+	if err := os.WriteFile(name, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tc := timecode.New(10*time.Hour, timecode.Rate25)
+	if err := SetTimecode(f, tc, 48000); err != nil {
+		t.Errorf("SetTimecode failed: %v", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := ReadTimeReference(f)
+	if err != nil || n != 10*3600*48000 {
+		t.Errorf("Wrong time reference: expected=%d got=%d (%v)", 10*3600*48000, n, err)
+	}
+	// all other bytes are unchanged
+	x, _ := os.ReadFile(name)
+	off := bytes.Index(b, []byte("bext")) + 8 + TimeReferenceOffset
+	if !bytes.Equal(x[:off], b[:off]) || !bytes.Equal(x[off+8:], b[off+8:]) {
+		t.Errorf("Unexpected changes outside the time reference")
+	}
+	// negative timecodes wrap at 24h
+	if err := SetTimecode(f, timecode.New(-time.Second, timecode.Rate25), 48000); err != nil {
+		t.Errorf("SetTimecode failed: %v", err)
+	}
+	f.Seek(0, io.SeekStart)
+	if n, _, _ := ReadTimeReference(f); n != 86399*48000 {
+		t.Errorf("Wrong time reference: expected=%d got=%d", 86399*48000, n)
+	}
+	g, _ := os.Create(filepath.Join(t.TempDir(), "nobext.wav"))
+	defer g.Close()
+	g.Write(wave("RIFF", fmtChunk(48000)))
+	if err := WriteTimeReference(g, 0); err != ErrBext {
+		t.Errorf("Expected missing bext error, got %v", err)
+	}
+}
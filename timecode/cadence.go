@@ -0,0 +1,226 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Cadence conversion
+//
+// SetRate keeps a timecode's frame counter, which is right for relabeling
+// but not for material that was transferred to a different rate. Film at
+// 24 fps reaches 30 fps video by pulldown: every source frame is spread over
+// two or three video fields following a repeating cadence.
+//
+//   2:3         A A B B B C C D D D    (AA BB BC CD DD)
+//   2:3:3:2     A A B B B C C C D D    (AA BB BC CC DD)
+//   2:2         A A                    (AA)
+//
+// Each cycle of the 2:3 cadences turns 4 source frames into 10 fields or 5
+// video frames. Interlaced video frames are built from two fields, so some
+// frames mix fields of two source frames. Advanced pulldown 2:3:3:2 keeps
+// only one mixed frame per cycle, which makes removal lossless by dropping
+// that frame. Targets at twice the video frame rate, e.g. 59.94p, count
+// fields as frames.
+//
+// The A-frame is the source frame that starts a cycle. Its position in the
+// target timeline, the A-frame phase, must be known to map frames in both
+// directions and is set with SetAnchor.
+//
+// PAL speedup plays 24 fps material at 25 fps. Frames map one to one, so
+// timecodes keep their frame counter while durations shrink by 4%. 2:2
+// pulldown maps progressive frames to interlaced frames of the same rate or
+// to two frames at twice the rate.
+
+package timecode
+
+import (
+	"fmt"
+)
+
+// Cadence selects a pulldown pattern for rate conversion.
+type Cadence int
+
+const (
+	Pulldown23 Cadence = iota
+	Pulldown2332
+	PulldownPAL
+	Pulldown22
+)
+
+// cadencePatterns holds the number of fields per source frame in a cycle.
+var cadencePatterns = map[Cadence][]int64{
+	Pulldown23:   {2, 3, 2, 3},
+	Pulldown2332: {2, 3, 3, 2},
+	PulldownPAL:  {2},
+	Pulldown22:   {2},
+}
+
+// String returns the name of the cadence.
+func (c Cadence) String() string {
+	switch c {
+	case Pulldown23:
+		return "2:3"
+	case Pulldown2332:
+		return "2:3:3:2"
+	case PulldownPAL:
+		return "PAL speedup"
+	case Pulldown22:
+		return "2:2"
+	default:
+		return "unknown"
+	}
+}
+
+// CadenceConverter maps frames between a source rate and a target rate
+// that differ by a pulldown cadence.
+type CadenceConverter struct {
+	cadence Cadence
+	src     Rate
+	dst     Rate
+	pattern []int64
+	prefix  []int64 // first field of each source frame in a cycle
+	fields  int64   // fields per cycle
+	width   int64   // fields per target frame
+	srcA    int64
+	dstA    int64
+}
+
+// NewCadenceConverter creates a converter from rate src to rate dst using
+// cadence c. The A-frame of source frame 0 starts at target frame 0 until
+// changed with SetAnchor.
+//
+// 2:3 and 2:3:3:2 convert 24 fps to 30 fps or 60 fps, e.g. Rate23976 to
+// Rate30DF, Rate30 or Rate5994. Rates are matched by nominal frames per
+// second because the mapping counts frames, not time. PAL speedup
+// converts 24 fps to 25 fps and 2:2 converts 25 fps or 30 fps to the same
+// or twice the frame rate.
+func NewCadenceConverter(c Cadence, src, dst Rate) (*CadenceConverter, error) {
+	p, ok := cadencePatterns[c]
+	if !ok {
+		return nil, fmt.Errorf("timecode: unknown cadence %d", int(c))
+	}
+	if !src.IsValid() || !dst.IsValid() {
+		return nil, fmt.Errorf("timecode: invalid rate for %s cadence", c)
+	}
+	x := &CadenceConverter{
+		cadence: c,
+		src:     src,
+		dst:     dst,
+		pattern: p,
+		prefix:  make([]int64, len(p)+1),
+		width:   2,
+	}
+	for i, v := range p {
+		x.prefix[i+1] = x.prefix[i] + v
+	}
+	x.fields = x.prefix[len(p)]
+	sf, df := int64(src.fps), int64(dst.fps)
+	n := int64(len(p))
+	switch {
+	case c == PulldownPAL:
+		ok = sf == 24 && df == 25
+	case c == Pulldown22 && sf != 25 && sf != 30:
+		ok = false
+	case (c == Pulldown23 || c == Pulldown2332) && sf != 24:
+		ok = false
+	case sf*x.fields == df*n*2:
+	case sf*x.fields == df*n:
+		x.width = 1
+	default:
+		ok = false
+	}
+	if !ok {
+		return nil, fmt.Errorf("timecode: cannot convert %s to %s with %s cadence", src.FloatString(), dst.FloatString(), c)
+	}
+	return x, nil
+}
+
+// Cadence returns the converter's cadence.
+func (x *CadenceConverter) Cadence() Cadence {
+	return x.cadence
+}
+
+// SetAnchor sets the A-frame phase: the A-frame at src starts with the
+// first field of target frame dst.
+func (x *CadenceConverter) SetAnchor(src, dst Timecode) {
+	x.srcA = src.FrameAtRate(x.src)
+	x.dstA = dst.FrameAtRate(x.dst)
+}
+
+// Phase returns the position of source frame t in the cadence cycle where
+// 0 is the A-frame.
+func (x *CadenceConverter) Phase(t Timecode) int {
+	_, pos := floorDivMod(t.FrameAtRate(x.src)-x.srcA, int64(len(x.pattern)))
+	return int(pos)
+}
+
+// ToTarget returns the first target frame that starts with source frame t.
+// Mixed frames that start with the previous source frame are skipped, e.g.
+// the C-frame of 2:3 pulldown maps to CD and not to BC.
+func (x *CadenceConverter) ToTarget(t Timecode) Timecode {
+	f := x.field(t.FrameAtRate(x.src))
+	return NewFrameCode(x.dstA+floorDiv(f+x.width-1, x.width), x.dst).Timecode()
+}
+
+// ToSource returns the source frame shown in the first field of target
+// frame t. This reverses ToTarget for pulldown removal. Target frames that
+// ToTarget never returns, such as mixed frames and repeated frames, map to
+// the same source frame as their predecessor. Use Fields or IsMixed to find
+// them.
+func (x *CadenceConverter) ToSource(t Timecode) Timecode {
+	a, _ := x.Fields(t)
+	return a
+}
+
+// Fields returns the source frames shown in the first and the last field
+// of target frame t. Both are equal unless t mixes two source frames.
+func (x *CadenceConverter) Fields(t Timecode) (Timecode, Timecode) {
+	f := (t.FrameAtRate(x.dst) - x.dstA) * x.width
+	a := NewFrameCode(x.frame(f), x.src).Timecode()
+	b := NewFrameCode(x.frame(f+x.width-1), x.src).Timecode()
+	return a, b
+}
+
+// IsMixed indicates if target frame t holds fields of two source frames.
+func (x *CadenceConverter) IsMixed(t Timecode) bool {
+	a, b := x.Fields(t)
+	return a != b
+}
+
+// field returns the first field of source frame f relative to the anchor.
+func (x *CadenceConverter) field(f int64) int64 {
+	cycle, pos := floorDivMod(f-x.srcA, int64(len(x.pattern)))
+	return cycle*x.fields + x.prefix[pos]
+}
+
+// frame returns the source frame shown in field f relative to the anchor.
+func (x *CadenceConverter) frame(f int64) int64 {
+	cycle, pos := floorDivMod(f, x.fields)
+	i := int64(0)
+	for pos >= x.prefix[i+1] {
+		i++
+	}
+	return x.srcA + cycle*int64(len(x.pattern)) + i
+}
+
+func floorDiv(a, b int64) int64 {
+	q, _ := floorDivMod(a, b)
+	return q
+}
+
+func floorDivMod(a, b int64) (int64, int64) {
+	q, m := a/b, a%b
+	if m < 0 {
+		q--
+		m += b
+	}
+	return q, m
+}
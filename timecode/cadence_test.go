@@ -0,0 +1,141 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"strings"
+	"testing"
+)
+
+// cadenceFields returns the source frame letters of target frames 0 to n
+// as written in pulldown diagrams.
+func cadenceFields(x *CadenceConverter, n int64) string {
+	s := make([]string, n)
+	for i := range s {
+		a, b := x.Fields(NewFrameCode(int64(i), x.dst).Timecode())
+		s[i] = string(rune('A'+a.Frame()%4)) + string(rune('A'+b.Frame()%4))
+	}
+	return strings.Join(s, " ")
+}
+
+type CadenceTestcase struct {
+	Id      string
+	Cadence Cadence
+	Src     Rate
+	Dst     Rate
+	Fields  string
+}
+
+var CadenceTestcases []CadenceTestcase = []CadenceTestcase{
+	{"2_3", Pulldown23, Rate23976, Rate30DF, "AA BB BC CD DD AA BB BC CD DD"},
+	{"2_3_30", Pulldown23, Rate23976, Rate30, "AA BB BC CD DD"},
+	{"2_3_24", Pulldown23, Rate24, Rate30, "AA BB BC CD DD"},
+	{"2_3_59_94", Pulldown23, Rate23976, Rate5994, "AA AA BB BB BB CC CC DD DD DD"},
+	{"2_3_3_2", Pulldown2332, Rate23976, Rate30DF, "AA BB BC CC DD AA BB BC CC DD"},
+	{"PAL", PulldownPAL, Rate24, Rate25, "AA BB CC DD AA"},
+	{"2_2", Pulldown22, Rate25, Rate25, "AA BB CC DD"},
+	{"2_2_50", Pulldown22, Rate25, Rate50, "AA AA BB BB CC CC"},
+	{"2_2_60", Pulldown22, Rate30DF, Rate60DF, "AA AA BB BB"},
+}
+
+func TestCadence(t *testing.T) {
+	for _, v := range CadenceTestcases {
+		x, err := NewCadenceConverter(v.Cadence, v.Src, v.Dst)
+		if err != nil {
+			t.Errorf("[Case #%s] NewCadenceConverter failed: %v", v.Id, err)
+			continue
+		}
+		n := int64(len(strings.Split(v.Fields, " ")))
+		if s := cadenceFields(x, n); s != v.Fields {
+			t.Errorf("[Case #%s] Wrong cadence: expected=%s got=%s", v.Id, v.Fields, s)
+		}
+		// every source frame maps to a target frame that starts with it
+		for f := int64(-20); f < 20; f++ {
+			src := NewFrameCode(f, v.Src).Timecode()
+			dst := x.ToTarget(src)
+			if dst.Rate() != v.Dst {
+				t.Errorf("[Case #%s] Wrong rate: %s", v.Id, dst.StringWithRate())
+			}
+			if y := x.ToSource(dst); y != src {
+				t.Errorf("[Case #%s] Wrong reverse mapping of %d: expected=%s got=%s via %s", v.Id, f, src, y, dst)
+			}
+		}
+	}
+}
+
+func TestCadenceAnchor(t *testing.T) {
+	x, _ := NewCadenceConverter(Pulldown23, Rate23976, Rate30DF)
+	x.SetAnchor(mustTimecode("01:00:00:00", Rate23976), mustTimecode("01:00:00;00", Rate30DF))
+	for i, v := range []struct {
+		Src   string
+		Dst   string
+		Phase int
+		Mixed bool
+	}{
+		{"01:00:00:00", "01:00:00;00", 0, false},
+		{"01:00:00:01", "01:00:00;01", 1, false},
+		{"01:00:00:02", "01:00:00;03", 2, true},
+		{"01:00:00:03", "01:00:00;04", 3, false},
+		{"01:00:00:04", "01:00:00;05", 0, false},
+		{"00:59:59:23", "00:59:59;29", 3, false},
+		{"01:00:01:00", "01:00:01;00", 0, false},
+		{"01:01:00:00", "01:01:00;02", 0, false},
+	} {
+		src := mustTimecode(v.Src, Rate23976)
+		dst := x.ToTarget(src)
+		if dst.String() != v.Dst {
+			t.Errorf("[Case #%d] Wrong target: expected=%s got=%s", i, v.Dst, dst)
+		}
+		if p := x.Phase(src); p != v.Phase {
+			t.Errorf("[Case #%d] Wrong phase: expected=%d got=%d", i, v.Phase, p)
+		}
+		if s := x.ToSource(dst); s != src {
+			t.Errorf("[Case #%d] Wrong source: expected=%s got=%s", i, src, s)
+		}
+		if m := x.IsMixed(dst); m != v.Mixed {
+			t.Errorf("[Case #%d] Wrong mixed flag: expected=%t got=%t", i, v.Mixed, m)
+		}
+	}
+	// the mixed BC frame is dropped on removal
+	bc := mustTimecode("01:00:00;02", Rate30DF)
+	if a, b := x.Fields(bc); a.String() != "01:00:00:01" || b.String() != "01:00:00:02" || x.ToSource(bc) != a {
+		t.Errorf("Wrong fields: expected=01:00:00:01/01:00:00:02 got=%s/%s", a, b)
+	}
+}
+
+func TestCadenceInvalid(t *testing.T) {
+	for i, v := range []struct {
+		Cadence Cadence
+		Src     Rate
+		Dst     Rate
+	}{
+		{Pulldown23, Rate25, Rate30DF},
+		{Pulldown23, Rate23976, Rate25},
+		{Pulldown2332, Rate30DF, Rate23976},
+		{Pulldown23, Rate48, Rate60},
+		{Pulldown23, NewRate(12, 1), Rate30},
+		{Pulldown2332, Rate48, Rate5994},
+		{PulldownPAL, Rate24, Rate30},
+		{PulldownPAL, Rate25, Rate24},
+		{Pulldown22, Rate24, Rate48},
+		{Pulldown22, Rate25, Rate30},
+		{Pulldown23, InvalidRate, Rate30},
+		{Cadence(99), Rate24, Rate30},
+	} {
+		if _, err := NewCadenceConverter(v.Cadence, v.Src, v.Dst); err == nil {
+			t.Errorf("[Case #%d] Expected error for %s to %s", i, v.Src.FloatString(), v.Dst.FloatString())
+		}
+	}
+}
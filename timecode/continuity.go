@@ -0,0 +1,229 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Timecode continuity
+//
+// Tapes and files with per-frame timecode are expected to count one frame
+// per picture. A continuity checker consumes these timecodes in stream order
+// and reports every value that does not follow its predecessor. After a
+// break the checker continues counting from the new value.
+//
+// Timecodes are compared in the 24h address range. Steps of more than 12
+// hours are treated as midnight rollover in either direction, so a step
+// from late in the day to early in the day continues forward across
+// midnight and a step from early to late in the day goes backward across
+// midnight.
+//
+// Drop-frame labeling errors are timecodes with a drop-frame flag or
+// separator that does not match the stream's rate and labels that
+// drop-frame counting skips, such as 00:01:00;00 at 29.97 fps. Skipped
+// labels can only be detected where the address label is still available,
+// i.e. for packed SMPTE words and for timecodes parsed without rate.
+
+package timecode
+
+import (
+	"fmt"
+	"time"
+)
+
+// ContinuityKind identifies the type of a continuity event.
+type ContinuityKind int
+
+const (
+	// ContinuityJump reports a timecode after the expected timecode.
+	ContinuityJump ContinuityKind = iota
+	// ContinuityRepeat reports a timecode equal to its predecessor.
+	ContinuityRepeat
+	// ContinuityBackward reports a timecode before its predecessor.
+	ContinuityBackward
+	// ContinuityDropFrame reports a drop-frame flag that does not match the
+	// stream's rate or a label that is skipped in drop-frame counting.
+	ContinuityDropFrame
+	// ContinuityRollover reports a step across midnight.
+	ContinuityRollover
+)
+
+// String returns the name of the event kind.
+func (k ContinuityKind) String() string {
+	switch k {
+	case ContinuityJump:
+		return "jump"
+	case ContinuityRepeat:
+		return "repeat"
+	case ContinuityBackward:
+		return "backward"
+	case ContinuityDropFrame:
+		return "drop-frame"
+	case ContinuityRollover:
+		return "rollover"
+	default:
+		return "unknown"
+	}
+}
+
+// ContinuityEvent describes a discontinuity at a position in the stream.
+type ContinuityEvent struct {
+	Kind ContinuityKind
+	// Index is the zero-based position of the timecode in the stream.
+	Index int64
+	// Timecode is the timecode received.
+	Timecode Timecode
+	// Expected is the timecode that would have continued the stream. It is
+	// invalid for events on the first timecode.
+	Expected Timecode
+	// Frames is the number of frames skipped by a jump or the negative
+	// number of frames of a backwards step.
+	Frames int64
+}
+
+// String returns a description of the event.
+func (e ContinuityEvent) String() string {
+	s := fmt.Sprintf("%s at #%d: %s", e.Kind, e.Index, e.Timecode)
+	if e.Expected.IsValid() {
+		s += ", expected " + e.Expected.String()
+	}
+	if e.Frames != 0 {
+		s += fmt.Sprintf(" (%+d frames)", e.Frames)
+	}
+	return s
+}
+
+// ContinuityChecker detects discontinuities in a stream of timecodes at a
+// single rate.
+type ContinuityChecker struct {
+	rate  Rate
+	index int64
+	last  int64
+}
+
+// NewContinuityChecker creates a checker for timecodes at rate r.
+func NewContinuityChecker(r Rate) *ContinuityChecker {
+	return &ContinuityChecker{rate: r}
+}
+
+// Rate returns the stream's rate.
+func (c *ContinuityChecker) Rate() Rate {
+	return c.rate
+}
+
+// Reset restarts the checker at the beginning of a new stream.
+func (c *ContinuityChecker) Reset() {
+	c.index = 0
+	c.last = 0
+}
+
+// Next returns the timecode expected next or Invalid before the first
+// timecode was checked.
+func (c *ContinuityChecker) Next() Timecode {
+	if c.index == 0 {
+		return Invalid
+	}
+	return c.timecode(c.last + 1)
+}
+
+// Check consumes the next timecode of the stream and returns the events it
+// causes or nil when the stream continues as expected. Timecodes without
+// rate, as returned by Parse, take the stream's rate and their labels are
+// checked like those of SMPTE words.
+func (c *ContinuityChecker) Check(t Timecode) []ContinuityEvent {
+	r := t.Rate()
+	bad := r.IsDrop() != c.rate.IsDrop()
+	if r.enum == IdentityRate.enum || r.enum == IdentityRateDF.enum {
+		d := t.Abs().Duration()
+		l := int64(d/time.Second)*int64(c.rate.fps) + int64(d%time.Second)
+		bad = bad || c.skipped(l)
+		t.SetRate(c.rate)
+	}
+	var events []ContinuityEvent
+	if bad {
+		events = append(events, c.event(ContinuityDropFrame, t, 0))
+	}
+	return c.step(t.FrameAtRate(c.rate), events)
+}
+
+// CheckSMPTE consumes the next packed SMPTE timecode word of the stream
+// and returns the events it causes or nil when the stream continues as
+// expected. The address label is always decoded at the stream's rate.
+func (c *ContinuityChecker) CheckSMPTE(tc uint32) []ContinuityEvent {
+	r := c.rate
+	l := unpackLabel(tc, r)
+	t := New(r.Duration(labelToFrame(l, r)), r)
+	var events []ContinuityEvent
+	if (tc&smpteDropFrame > 0) != r.IsDrop() || c.skipped(l) {
+		events = append(events, c.event(ContinuityDropFrame, t, 0))
+	}
+	return c.step(t.Frame(), events)
+}
+
+// skipped indicates if label counter l is not used in drop-frame counting
+// at the stream's rate.
+func (c *ContinuityChecker) skipped(l int64) bool {
+	r := c.rate
+	if !r.IsDrop() || r.dropFrames == 0 {
+		return false
+	}
+	fpm := int64(r.fps) * 60
+	m := l / fpm
+	return m%10 != 0 && l%fpm < int64(r.dropFrames)
+}
+
+// step advances the stream to frame f.
+func (c *ContinuityChecker) step(f int64, events []ContinuityEvent) []ContinuityEvent {
+	n := c.rate.FramesPerDay()
+	if f %= n; f < 0 {
+		f += n
+	}
+	t := c.timecode(f)
+	if c.index > 0 {
+		next := c.last + 1
+		switch {
+		case f == next || f == 0 && next == n:
+			if f == 0 {
+				events = append(events, c.event(ContinuityRollover, t, 0))
+			}
+		case f == c.last:
+			events = append(events, c.event(ContinuityRepeat, t, 0))
+		case f < c.last && c.last-f > n/2:
+			events = append(events, c.event(ContinuityRollover, t, 0))
+			events = append(events, c.event(ContinuityJump, t, f+n-next))
+		case f > c.last && f-c.last > n/2:
+			events = append(events, c.event(ContinuityRollover, t, 0))
+			events = append(events, c.event(ContinuityBackward, t, f-n-c.last))
+		case f > c.last:
+			events = append(events, c.event(ContinuityJump, t, f-next))
+		default:
+			events = append(events, c.event(ContinuityBackward, t, f-c.last))
+		}
+	}
+	c.last = f
+	c.index++
+	return events
+}
+
+func (c *ContinuityChecker) event(k ContinuityKind, t Timecode, frames int64) ContinuityEvent {
+	return ContinuityEvent{
+		Kind:     k,
+		Index:    c.index,
+		Timecode: t,
+		Expected: c.Next(),
+		Frames:   frames,
+	}
+}
+
+// timecode returns frame f in the 24h address range at the stream's rate.
+func (c *ContinuityChecker) timecode(f int64) Timecode {
+	t, _ := NewFrameCode(f, c.rate).Timecode().Wrap()
+	return t
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"strings"
+	"testing"
+)
+
+func continuityEvents(c *ContinuityChecker, list []string) string {
+	var s []string
+	for _, v := range list {
+		t, err := Parse(v)
+		if err != nil {
+			panic(err)
+		}
+		for _, e := range c.Check(t) {
+			s = append(s, e.String())
+		}
+	}
+	return strings.Join(s, "; ")
+}
+
+type ContinuityTestcase struct {
+	Id     string
+	Rate   Rate
+	Stream []string
+	Events string
+}
+
+var ContinuityTestcases []ContinuityTestcase = []ContinuityTestcase{
+	{"continuous", Rate25,
+		[]string{"10:00:00:23", "10:00:00:24", "10:00:01:00", "10:00:01:01"},
+		"",
+	},
+	{"jump", Rate25,
+		[]string{"10:00:00:00", "10:00:00:01", "10:00:00:05", "10:00:00:06"},
+		"jump at #2: 10:00:00:05, expected 10:00:00:02 (+3 frames)",
+	},
+	{"repeat", Rate25,
+		[]string{"10:00:00:00", "10:00:00:01", "10:00:00:01", "10:00:00:02"},
+		"repeat at #2: 10:00:00:01, expected 10:00:00:02",
+	},
+	{"backward", Rate24,
+		[]string{"10:00:00:00", "10:00:00:01", "09:59:59:23", "10:00:00:00"},
+		"backward at #2: 09:59:59:23, expected 10:00:00:02 (-2 frames)",
+	},
+	{"rollover", Rate25,
+		[]string{"23:59:59:23", "23:59:59:24", "00:00:00:00", "00:00:00:01"},
+		"rollover at #2: 00:00:00:00, expected 00:00:00:00",
+	},
+	{"rollover_jump", Rate25,
+		[]string{"23:59:59:24", "00:00:01:00"},
+		"rollover at #1: 00:00:01:00, expected 00:00:00:00; jump at #1: 00:00:01:00, expected 00:00:00:00 (+25 frames)",
+	},
+	{"backward_half_day", Rate25,
+		[]string{"13:00:00:00", "01:00:00:01"},
+		"backward at #1: 01:00:00:01, expected 13:00:00:01 (-1079999 frames)",
+	},
+	{"rollover_backward", Rate25,
+		[]string{"00:00:00:00", "00:00:00:01", "23:59:59:24"},
+		"rollover at #2: 23:59:59:24, expected 00:00:00:02; backward at #2: 23:59:59:24, expected 00:00:00:02 (-2 frames)",
+	},
+	{"rollover_backward_half_day", Rate25,
+		[]string{"01:00:00:00", "13:00:00:01"},
+		"rollover at #1: 13:00:00:01, expected 01:00:00:01; backward at #1: 13:00:00:01, expected 01:00:00:01 (-1079999 frames)",
+	},
+	{"jump_half_day", Rate25,
+		[]string{"01:00:00:00", "13:00:00:00"},
+		"jump at #1: 13:00:00:00, expected 01:00:00:01 (+1079999 frames)",
+	},
+	{"rollover_jump_half_day", Rate25,
+		[]string{"13:00:00:01", "01:00:00:00"},
+		"rollover at #1: 01:00:00:00, expected 13:00:00:02; jump at #1: 01:00:00:00, expected 13:00:00:02 (+1079998 frames)",
+	},
+	{"29_97DF_minute", Rate30DF,
+		[]string{"00:00:59;28", "00:00:59;29", "00:01:00;02", "00:01:00;03"},
+		"",
+	},
+	{"29_97DF_10min", Rate30DF,
+		[]string{"00:09:59;29", "00:10:00;00", "00:10:00;01"},
+		"",
+	},
+	{"29_97DF_skipped", Rate30DF,
+		[]string{"00:00:59;29", "00:01:00;00", "00:01:00;03"},
+		"drop-frame at #1: 00:01:00;02, expected 00:01:00;02",
+	},
+	{"29_97DF_separator", Rate30DF,
+		[]string{"00:00:10;00", "00:00:10:01", "00:00:10;02"},
+		"drop-frame at #1: 00:00:10;01, expected 00:00:10;01",
+	},
+	{"30_separator", Rate30,
+		[]string{"00:00:10:00", "00:00:10;01"},
+		"drop-frame at #1: 00:00:10:01, expected 00:00:10:01",
+	},
+	{"first", Rate30DF,
+		[]string{"00:00:10:00"},
+		"drop-frame at #0: 00:00:10;00",
+	},
+}
+
+func TestContinuity(t *testing.T) {
+	for _, v := range ContinuityTestcases {
+		c := NewContinuityChecker(v.Rate)
+		if x := continuityEvents(c, v.Stream); x != v.Events {
+			t.Errorf("[Case #%s] Wrong events:\nexpected=%s\n     got=%s", v.Id, v.Events, x)
+		}
+	}
+}
+
+func TestContinuityRate(t *testing.T) {
+	c := NewContinuityChecker(Rate30DF)
+	tc := New(0, Rate30DF)
+	for i := 0; i < 20000; i++ {
+		if e := c.Check(tc); e != nil {
+			t.Errorf("[Case #%d] Unexpected events: %v", i, e)
+			break
+		}
+		tc = tc.AddFrames(1)
+	}
+	if x := c.Next(); x != tc {
+		t.Errorf("Wrong next timecode: expected=%s got=%s", tc, x)
+	}
+	// timecodes at other rates are compared by frame
+	if e := c.Check(New(tc.Duration(), Rate5994)); len(e) != 1 || e[0].Kind != ContinuityDropFrame {
+		t.Errorf("Wrong events: %v", e)
+	}
+	c.Reset()
+	if x := c.Next(); x != Invalid {
+		t.Errorf("Wrong next timecode after reset: %s", x)
+	}
+}
+
+func TestContinuitySMPTE(t *testing.T) {
+	c := NewContinuityChecker(Rate30DF)
+	var events []ContinuityEvent
+	for i, v := range []uint32{
+		0x00005928 | smpteDropFrame,
+		0x00005929 | smpteDropFrame,
+		0x00010000 | smpteDropFrame, // skipped label
+		0x00010003 | smpteDropFrame,
+		0x00010004, // missing flag
+		0x00010005 | smpteDropFrame,
+		0x00010005 | smpteDropFrame,
+		0x23595929 | smpteDropFrame,
+		0x00000000 | smpteDropFrame,
+	} {
+		for _, e := range c.CheckSMPTE(v) {
+			if e.Index != int64(i) {
+				t.Errorf("[Case #%d] Wrong index %d", i, e.Index)
+			}
+			events = append(events, e)
+		}
+	}
+	expected := []ContinuityKind{
+		ContinuityDropFrame,
+		ContinuityDropFrame,
+		ContinuityRepeat,
+		ContinuityRollover,
+		ContinuityBackward,
+		ContinuityRollover,
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Wrong events: %v", events)
+	}
+	for i, v := range expected {
+		if events[i].Kind != v {
+			t.Errorf("[Case #%d] Wrong event: expected=%s got=%s", i, v, events[i])
+		}
+	}
+	// the skipped label resolves to the next valid label
+	if x := events[0].Timecode.String(); x != "00:01:00;02" {
+		t.Errorf("Wrong timecode: expected=00:01:00;02 got=%s", x)
+	}
+	// forward steps across most of the day go backward across midnight
+	if x := events[4].Frames; x != -1804 {
+		t.Errorf("Wrong backward step: expected=-1804 got=%d", x)
+	}
+}
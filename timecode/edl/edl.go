@@ -0,0 +1,426 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package edl reads and writes CMX 3600 edit decision lists.
+//
+// An EDL starts with a title and a frame code mode (FCM) line followed by
+// one line per event:
+//
+//	TITLE: PROJECT
+//	FCM: DROP FRAME
+//
+//	001  AX       V     C        01:00:00;00 01:00:05;00 00:59:58;00 01:00:03;00
+//	M2   AX       048.0          01:00:00;00
+//	* FROM CLIP NAME: A001C003.MOV
+//
+// FCM lines select drop-frame or non-drop-frame timecodes for all events
+// that follow. Transitions span two events with the same number, the first
+// one holds the outgoing source and the second one the incoming source
+// together with the transition type and duration. M2 lines attach a speed
+// change to the preceding event and all other lines following an event are
+// kept as its comments. Longer reel names, 'AUD' lines and other Avid and
+// Premiere extensions are accepted.
+//
+// EDLs keep the original text of all lines. Unchanged events are written
+// back byte-identical while changed and new events are written in CMX 3600
+// column layout.
+package edl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+// Common transition types.
+const (
+	Cut      = "C"
+	Dissolve = "D"
+	Wipe     = "W"
+	Key      = "K"
+)
+
+var (
+	ErrEvent  = errors.New("edl: invalid event")
+	ErrMotion = errors.New("edl: invalid motion effect")
+	ErrFCM    = errors.New("edl: invalid frame code mode")
+	ErrRate   = errors.New("edl: no drop-frame variant of rate")
+)
+
+// ParseError reports the line of an EDL that failed to parse.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v in line %d", e.Err, e.Line)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// EDL is an edit decision list.
+type EDL struct {
+	Title string
+	// Rate is the timecode rate selected by the header's FCM line.
+	Rate   timecode.Rate
+	Events []*Event
+
+	eol     string
+	header  string
+	snap    string
+	trailer string
+}
+
+// Event is a single edit. All timecodes of an event share the rate that
+// was selected by the last FCM line before the event.
+type Event struct {
+	Number     int
+	Reel       string
+	Track      string
+	Transition string
+	// Duration is the transition duration in frames.
+	Duration  int
+	SourceIn  timecode.Timecode
+	SourceOut timecode.Timecode
+	RecordIn  timecode.Timecode
+	RecordOut timecode.Timecode
+	// Motion is the speed change from an M2 line.
+	Motion *Motion
+	// Comments holds the text of all other lines following the event.
+	Comments []string
+
+	raw  string
+	snap string
+	fcm  bool
+}
+
+// Motion is a speed change from an M2 line.
+type Motion struct {
+	Reel string
+	// Speed is the playback speed in frames per second. Negative speeds
+	// play in reverse, zero is a freeze frame.
+	Speed float64
+	// Entry is the source timecode where the effect starts.
+	Entry timecode.Timecode
+}
+
+// Parse reads an EDL from r. Timecodes use rate base or its drop-frame
+// variant when an FCM line asks for drop-frame. Without a valid base rate
+// timecode.Rate30 is assumed, so that drop-frame EDLs use
+// timecode.Rate30DF.
+func Parse(r io.Reader, base timecode.Rate) (*EDL, error) {
+	if !base.IsValid() {
+		base = timecode.Rate30
+	}
+	l := &EDL{Rate: base}
+	var (
+		br      = bufio.NewReader(r)
+		rate    = base
+		e       *Event
+		pending strings.Builder
+		fcm     bool
+	)
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if len(line) == 0 {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if n == 1 && strings.HasSuffix(line, "\r\n") {
+			l.eol = "\r\n"
+		}
+		text := strings.TrimSpace(line)
+		switch {
+		case e == nil && hasPrefixFold(text, "TITLE:"):
+			l.Title = strings.TrimSpace(text[6:])
+			l.header += line
+		case hasPrefixFold(text, "FCM:"):
+			r, err := fcmRate(text[4:], base)
+			if err != nil {
+				return nil, &ParseError{n, err}
+			}
+			rate = r
+			if e == nil {
+				l.Rate = rate
+				l.header += line
+			} else {
+				pending.WriteString(line)
+				fcm = true
+			}
+		case len(text) > 0 && text[0] >= '0' && text[0] <= '9':
+			x, err := parseEvent(text, rate)
+			if err != nil {
+				return nil, &ParseError{n, err}
+			}
+			x.raw = pending.String() + line
+			x.fcm = fcm
+			pending.Reset()
+			fcm = false
+			l.Events = append(l.Events, x)
+			e = x
+		case e == nil:
+			l.header += line
+		case fcm:
+			pending.WriteString(line)
+		case hasPrefixFold(text, "M2"):
+			m, err := parseMotion(text, rate)
+			if err != nil {
+				return nil, &ParseError{n, err}
+			}
+			e.Motion = m
+			e.raw += line
+		default:
+			if len(text) > 0 {
+				e.Comments = append(e.Comments, strings.TrimRight(line, "\r\n"))
+			}
+			e.raw += line
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	l.trailer = pending.String()
+	l.snap = l.formatHeader("\n")
+	for _, e := range l.Events {
+		e.snap = e.format("\n")
+	}
+	return l, nil
+}
+
+// Note returns the value of the first comment of form '* KEY: value', for
+// example the clip name from '* FROM CLIP NAME:'. Keys are matched without
+// regard to case.
+func (e *Event) Note(key string) string {
+	for _, c := range e.Comments {
+		c = strings.TrimSpace(c)
+		if !strings.HasPrefix(c, "*") {
+			continue
+		}
+		c = strings.TrimSpace(c[1:])
+		if hasPrefixFold(c, key) && strings.HasPrefix(c[len(key):], ":") {
+			return strings.TrimSpace(c[len(key)+1:])
+		}
+	}
+	return ""
+}
+
+// Rate returns the timecode rate of the event.
+func (e *Event) Rate() timecode.Rate {
+	return e.RecordIn.Rate()
+}
+
+// String returns the event line in CMX 3600 column layout.
+func (e *Event) String() string {
+	dur := ""
+	if e.Transition != Cut {
+		dur = fmt.Sprintf("%03d", e.Duration)
+	}
+	return fmt.Sprintf("%03d  %-8s %-5s %-4s %3s %s %s %s %s",
+		e.Number, e.Reel, e.Track, e.Transition, dur,
+		e.SourceIn, e.SourceOut, e.RecordIn, e.RecordOut)
+}
+
+// String returns the M2 line of a motion effect.
+func (m *Motion) String() string {
+	return fmt.Sprintf("M2   %-8s %05.1f          %s", m.Reel, m.Speed, m.Entry)
+}
+
+// String returns the EDL as text.
+func (l *EDL) String() string {
+	var b strings.Builder
+	l.WriteTo(&b)
+	return b.String()
+}
+
+// WriteTo writes the EDL to w. Unchanged parts use their original text.
+func (l *EDL) WriteTo(w io.Writer) (int64, error) {
+	eol := l.eol
+	if eol == "" {
+		eol = "\n"
+	}
+	var b strings.Builder
+	write := func(s string) {
+		// text that was read without a final line break
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString(eol)
+		}
+		b.WriteString(s)
+	}
+	if l.snap != "" && l.formatHeader("\n") == l.snap {
+		write(l.header)
+	} else {
+		write(l.formatHeader(eol))
+	}
+	cur := l.Rate
+	for _, e := range l.Events {
+		r := e.Rate()
+		if e.snap != "" && e.format("\n") == e.snap && (e.fcm || r.IsDrop() == cur.IsDrop()) {
+			write(e.raw)
+		} else {
+			if r.IsDrop() != cur.IsDrop() {
+				write(formatFCM(r) + eol)
+			}
+			write(e.format(eol) + blankTail(e.raw))
+		}
+		cur = r
+	}
+	if l.trailer != "" {
+		write(l.trailer)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// blankTail returns the blank lines at the end of raw event text, so that
+// edited events keep their spacing to the next event.
+func blankTail(raw string) string {
+	lines := strings.SplitAfter(raw, "\n")
+	i := len(lines)
+	for i > 1 && strings.TrimSpace(lines[i-1]) == "" {
+		i--
+	}
+	return strings.Join(lines[i:], "")
+}
+
+func (l *EDL) formatHeader(eol string) string {
+	return "TITLE: " + l.Title + eol + formatFCM(l.Rate) + eol + eol
+}
+
+func (e *Event) format(eol string) string {
+	s := e.String() + eol
+	if e.Motion != nil {
+		s += e.Motion.String() + eol
+	}
+	for _, c := range e.Comments {
+		s += c + eol
+	}
+	return s
+}
+
+func formatFCM(r timecode.Rate) string {
+	if r.IsDrop() {
+		return "FCM: DROP FRAME"
+	}
+	return "FCM: NON-DROP FRAME"
+}
+
+// fcmRate returns the rate selected by the FCM line value s.
+func fcmRate(s string, base timecode.Rate) (timecode.Rate, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	switch {
+	case strings.HasPrefix(s, "NON"):
+		return base.NonDrop(), nil
+	case strings.HasPrefix(s, "DROP"):
+		if r, ok := base.Drop(); ok {
+			return r, nil
+		}
+		fps := int(math.Round(float64(base.Float())))
+		if r, ok := timecode.NewRate(fps*1000, 1001).Drop(); ok {
+			return r, nil
+		}
+		return base, ErrRate
+	default:
+		return base, ErrFCM
+	}
+}
+
+// parseEvent reads an event line of form 'num reel track transition
+// [duration] srcIn srcOut recIn recOut'.
+func parseEvent(s string, r timecode.Rate) (*Event, error) {
+	f := strings.Fields(s)
+	if len(f) < 8 {
+		return nil, ErrEvent
+	}
+	num, err := strconv.Atoi(f[0])
+	if err != nil {
+		return nil, ErrEvent
+	}
+	e := &Event{
+		Number: num,
+		Reel:   f[1],
+		Track:  f[2],
+	}
+	// key transitions may be written as 'K B' or 'K O'
+	t := f[3 : len(f)-4]
+	if len(t) > 1 && isDigits(t[len(t)-1]) {
+		if e.Duration, err = strconv.Atoi(t[len(t)-1]); err != nil {
+			return nil, ErrEvent
+		}
+		t = t[:len(t)-1]
+	}
+	if len(t) == 0 || isDigits(t[0]) {
+		return nil, ErrEvent
+	}
+	e.Transition = strings.Join(t, " ")
+	tc := make([]timecode.Timecode, 4)
+	for i, v := range f[len(f)-4:] {
+		if tc[i], err = parseTimecode(v, r); err != nil {
+			return nil, ErrEvent
+		}
+	}
+	e.SourceIn, e.SourceOut, e.RecordIn, e.RecordOut = tc[0], tc[1], tc[2], tc[3]
+	return e, nil
+}
+
+// parseMotion reads an M2 line of form 'M2 reel speed entry'.
+func parseMotion(s string, r timecode.Rate) (*Motion, error) {
+	f := strings.Fields(s)
+	if len(f) != 4 || !strings.EqualFold(f[0], "M2") {
+		return nil, ErrMotion
+	}
+	speed, err := strconv.ParseFloat(f[2], 64)
+	if err != nil {
+		return nil, ErrMotion
+	}
+	entry, err := parseTimecode(f[3], r)
+	if err != nil {
+		return nil, ErrMotion
+	}
+	return &Motion{Reel: f[1], Speed: speed, Entry: entry}, nil
+}
+
+func parseTimecode(s string, r timecode.Rate) (timecode.Timecode, error) {
+	if strings.Count(s, ":")+strings.Count(s, ";") != 3 {
+		return timecode.Invalid, ErrEvent
+	}
+	f, err := timecode.ParseFrameCode(s, r)
+	if err != nil {
+		return timecode.Invalid, err
+	}
+	return f.Timecode(), nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package edl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+const cmx3600 = `TITLE: TEST REEL
+FCM: DROP FRAME
+
+001  AX       V     C        01:00:00;00 01:00:05;00 00:59:58;00 01:00:03;00
+* FROM CLIP NAME: A001C003.MOV
+
+002  AX       V     C        01:00:10;00 01:00:10;00 01:00:03;00 01:00:03;00
+002  BL       V     D    030 00:00:00;00 00:00:01;00 01:00:03;00 01:00:04;00
+* FROM CLIP NAME: A001C003.MOV
+* TO CLIP NAME: BLACK
+
+003  A002C001 AA/V  C        12:00:00:00 12:00:02:00 01:00:04;00 01:00:05;00
+M2   A002C001 048.0          12:00:00;00
+FCM: NON-DROP FRAME
+
+004  AX       A     W001 015 00:00:10:00 00:00:20:00 01:00:05:00 01:00:15:00
+`
+
+const avid = `TITLE:   AVID SEQUENCE
+FCM: NON-DROP FRAME
+0001  A001_C002_0101AB                 V     C        10:00:00:00 10:00:01:00 01:00:00:00 01:00:01:00
+AUD  3    4
+0002  A001_C003_0101AB                 A2/V  K B  012 10:00:00:00 10:00:01:00 01:00:01:00 01:00:02:00
+M2   A001_C003_0101AB -025.0                10:00:01:00
+* EFFECTS NAME IS KEY`
+
+func parse(t *testing.T, s string, r timecode.Rate) *EDL {
+	l, err := Parse(strings.NewReader(s), r)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return l
+}
+
+func TestParse(t *testing.T) {
+	l := parse(t, cmx3600, timecode.InvalidRate)
+	if l.Title != "TEST REEL" || l.Rate != timecode.Rate30DF || len(l.Events) != 5 {
+		t.Fatalf("Wrong EDL: title=%s rate=%s events=%d", l.Title, l.Rate.RationalString(), len(l.Events))
+	}
+	for i, v := range []struct {
+		Number     int
+		Reel       string
+		Track      string
+		Transition string
+		Duration   int
+		SourceIn   string
+		RecordOut  string
+		Rate       timecode.Rate
+	}{
+		{1, "AX", "V", "C", 0, "01:00:00;00", "01:00:03;00", timecode.Rate30DF},
+		{2, "AX", "V", "C", 0, "01:00:10;00", "01:00:03;00", timecode.Rate30DF},
+		{2, "BL", "V", "D", 30, "00:00:00;00", "01:00:04;00", timecode.Rate30DF},
+		{3, "A002C001", "AA/V", "C", 0, "12:00:00;00", "01:00:05;00", timecode.Rate30DF},
+		{4, "AX", "A", "W001", 15, "00:00:10:00", "01:00:15:00", timecode.Rate30},
+	} {
+		e := l.Events[i]
+		if e.Number != v.Number || e.Reel != v.Reel || e.Track != v.Track || e.Transition != v.Transition || e.Duration != v.Duration {
+			t.Errorf("[Case #%d] Wrong event: %+v", i, e)
+		}
+		if e.SourceIn.String() != v.SourceIn || e.RecordOut.String() != v.RecordOut {
+			t.Errorf("[Case #%d] Wrong timecodes: expected=%s/%s got=%s/%s", i, v.SourceIn, v.RecordOut, e.SourceIn, e.RecordOut)
+		}
+		if e.Rate() != v.Rate || e.SourceOut.Rate() != v.Rate {
+			t.Errorf("[Case #%d] Wrong rate: expected=%s got=%s", i, v.Rate.RationalString(), e.Rate().RationalString())
+		}
+	}
+	if n := l.Events[2].Note("to clip name"); n != "BLACK" {
+		t.Errorf("Wrong clip name: expected=BLACK got=%s", n)
+	}
+	if n := l.Events[0].Note("FROM CLIP NAME"); n != "A001C003.MOV" {
+		t.Errorf("Wrong clip name: expected=A001C003.MOV got=%s", n)
+	}
+	m := l.Events[3].Motion
+	if m == nil || m.Reel != "A002C001" || m.Speed != 48 || m.Entry.StringWithRate() != "12:00:00;00@29.970" {
+		t.Errorf("Wrong motion effect: %+v", m)
+	}
+	if len(l.Events[3].Comments) != 0 {
+		t.Errorf("Unexpected comments: %q", l.Events[3].Comments)
+	}
+}
+
+func TestParseAvid(t *testing.T) {
+	l := parse(t, avid, timecode.Rate25)
+	if l.Title != "AVID SEQUENCE" || l.Rate != timecode.Rate25 || len(l.Events) != 2 {
+		t.Fatalf("Wrong EDL: title=%s rate=%s events=%d", l.Title, l.Rate.RationalString(), len(l.Events))
+	}
+	e := l.Events[1]
+	if e.Reel != "A001_C003_0101AB" || e.Track != "A2/V" || e.Transition != "K B" || e.Duration != 12 {
+		t.Errorf("Wrong event: %+v", e)
+	}
+	if e.Motion == nil || e.Motion.Speed != -25 || e.Motion.Entry.StringWithRate() != "10:00:01:00@25.0" {
+		t.Errorf("Wrong motion effect: %+v", e.Motion)
+	}
+	if c := l.Events[0].Comments; len(c) != 1 || c[0] != "AUD  3    4" {
+		t.Errorf("Wrong comments: %q", c)
+	}
+}
+
+func TestRoundtrip(t *testing.T) {
+	for i, s := range []string{
+		cmx3600,
+		strings.Replace(cmx3600, "\n", "\r\n", -1),
+		strings.TrimSuffix(cmx3600, "\n"),
+		avid,
+		"001  AX       V     C        01:00:00:00 01:00:05:00 00:00:00:00 00:00:05:00\n",
+		"",
+	} {
+		l, err := Parse(strings.NewReader(s), timecode.Rate30)
+		if err != nil {
+			t.Errorf("[Case #%d] Parse failed: %v", i, err)
+			continue
+		}
+		if x := l.String(); x != s {
+			t.Errorf("[Case #%d] Output differs:\n%q\n%q", i, s, x)
+		}
+	}
+}
+
+func TestWriteChanged(t *testing.T) {
+	l := parse(t, cmx3600, timecode.Rate30)
+	e := l.Events[0]
+	e.RecordOut = e.RecordOut.AddFrames(30)
+	expected := strings.Replace(cmx3600,
+		"00:59:58;00 01:00:03;00\n* FROM CLIP NAME: A001C003.MOV\n\n",
+		"00:59:58;00 01:00:04;00\n* FROM CLIP NAME: A001C003.MOV\n\n", 1)
+	if s := l.String(); s != expected {
+		t.Errorf("Wrong output:\n%s\nexpected:\n%s", s, expected)
+	}
+	// a new drop-frame event after non-drop-frame events needs an FCM line
+	tc := func(s string) timecode.Timecode {
+		f, _ := timecode.ParseFrameCode(s, timecode.Rate30DF)
+		return f.Timecode()
+	}
+	l.Events = append(l.Events, &Event{
+		Number:     5,
+		Reel:       "AX",
+		Track:      "V",
+		Transition: Dissolve,
+		Duration:   10,
+		SourceIn:   tc("00:01:00;02"),
+		SourceOut:  tc("00:01:01;00"),
+		RecordIn:   tc("01:00:15;00"),
+		RecordOut:  tc("01:00:15;28"),
+		Motion:     &Motion{"AX", 0, tc("00:01:00;02")},
+		Comments:   []string{"* FREEZE FRAME"},
+	})
+	expected += "FCM: DROP FRAME\n" +
+		"005  AX       V     D    010 00:01:00;02 00:01:01;00 01:00:15;00 01:00:15;28\n" +
+		"M2   AX       000.0          00:01:00;02\n" +
+		"* FREEZE FRAME\n"
+	if s := l.String(); s != expected {
+		t.Errorf("Wrong output:\n%s\nexpected:\n%s", s, expected)
+	}
+	// changing the header rewrites it
+	l.Title = "NEW TITLE"
+	if s := l.String(); !strings.HasPrefix(s, "TITLE: NEW TITLE\nFCM: DROP FRAME\n\n001  AX") {
+		t.Errorf("Wrong header: %q", s[:40])
+	}
+}
+
+func TestWriteNew(t *testing.T) {
+	tc := func(s string) timecode.Timecode {
+		f, _ := timecode.ParseFrameCode(s, timecode.Rate25)
+		return f.Timecode()
+	}
+	l := &EDL{
+		Title: "PAL",
+		Rate:  timecode.Rate25,
+		Events: []*Event{
+			{Number: 1, Reel: "TAPE01", Track: "V", Transition: Cut,
+				SourceIn: tc("10:00:00:00"), SourceOut: tc("10:00:10:00"),
+				RecordIn: tc("01:00:00:00"), RecordOut: tc("01:00:10:00")},
+			{Number: 2, Reel: "TAPE02", Track: "A", Transition: Cut,
+				SourceIn: tc("10:00:00:00"), SourceOut: tc("10:00:10:00"),
+				RecordIn: tc("01:00:10:00"), RecordOut: tc("01:00:20:00"),
+				Comments: []string{"* FROM CLIP NAME: SOUND"}},
+		},
+	}
+	expected := "TITLE: PAL\n" +
+		"FCM: NON-DROP FRAME\n" +
+		"\n" +
+		"001  TAPE01   V     C        10:00:00:00 10:00:10:00 01:00:00:00 01:00:10:00\n" +
+		"002  TAPE02   A     C        10:00:00:00 10:00:10:00 01:00:10:00 01:00:20:00\n" +
+		"* FROM CLIP NAME: SOUND\n"
+	var b strings.Builder
+	n, err := l.WriteTo(&b)
+	if err != nil || n != int64(len(expected)) {
+		t.Errorf("WriteTo failed: n=%d err=%v", n, err)
+	}
+	if b.String() != expected {
+		t.Errorf("Wrong output:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+	x := parse(t, expected, timecode.Rate25)
+	for i, e := range x.Events {
+		if e.RecordIn != l.Events[i].RecordIn || e.SourceOut != l.Events[i].SourceOut {
+			t.Errorf("[Case #%d] Wrong event: %+v", i, e)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for i, v := range []struct {
+		Text string
+		Rate timecode.Rate
+		Line int
+		Err  error
+	}{
+		{"TITLE: X\n001  AX V C 01:00:00:00 01:00:01:00 01:00:00:00\n", timecode.Rate30, 2, ErrEvent},
+		{"001  AX V C 01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:0x\n", timecode.Rate30, 1, ErrEvent},
+		{"001  AX V 030 01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\n", timecode.Rate30, 1, ErrEvent},
+		{"001  AX V C 01:00:00 01:00:01:00 01:00:00:00 01:00:01:00\n", timecode.Rate30, 1, ErrEvent},
+		{"FCM: HALF FRAME\n", timecode.Rate30, 1, ErrFCM},
+		{"TITLE: PAL\nFCM: DROP FRAME\n", timecode.Rate25, 2, ErrRate},
+		{"001  AX V C 01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\nM2 AX fast 01:00:00:00\n", timecode.Rate30, 2, ErrMotion},
+		{"001  AX V C 01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\nM2 AX 25.0\n", timecode.Rate30, 2, ErrMotion},
+	} {
+		_, err := Parse(strings.NewReader(v.Text), v.Rate)
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Line != v.Line || !errors.Is(err, v.Err) {
+			t.Errorf("[Case #%d] Expected %v in line %d, got %v", i, v.Err, v.Line, err)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for i, v := range []struct {
+		FCM  string
+		Base timecode.Rate
+		Rate timecode.Rate
+	}{
+		{"DROP FRAME", timecode.Rate30, timecode.Rate30DF},
+		{"NON-DROP FRAME", timecode.Rate30, timecode.Rate30},
+		{"drop frame", timecode.Rate30DF, timecode.Rate30DF},
+		{"NON DROP FRAME", timecode.Rate30DF, timecode.Rate30DF.NonDrop()},
+		{"DROP FRAME", timecode.Rate5994, timecode.Rate60DF},
+		{"DROP FRAME", timecode.Rate60, timecode.Rate60DF},
+		{"NON-DROP FRAME", timecode.Rate25, timecode.Rate25},
+	} {
+		l := parse(t, "FCM: "+v.FCM+"\n", v.Base)
+		if l.Rate != v.Rate {
+			t.Errorf("[Case #%d] Wrong rate: expected=%s got=%s", i, v.Rate.RationalString(), l.Rate.RationalString())
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Feet and frames
+//
+// Film footage counts address frames as feet plus frames within the foot
+// in form 'ffff+ff'. A foot of 35mm film has 64 perforations and a foot of
+// 16mm film has 40. With 4-perf (16 frames per foot), 2-perf (32 frames per
+// foot) and 16mm (40 frames per foot) each foot holds the same number of
+// frames.
+//
+// 3-perf frames do not fit evenly into a foot. The pattern repeats every 3
+// feet or 64 frames (192 perforations) and, with the first frame starting
+// at the first perforation of foot 0, the feet of each cycle hold 22, 21
+// and 21 frames. Note that the cycle is sometimes quoted as 64 frames per
+// 4 feet, but 64 frames of 3 perforations each span exactly 3 feet.
+
+package timecode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Footage selects the film gauge and pulldown used for feet and frames
+// counts.
+type Footage int
+
+const (
+	Footage35mm4Perf Footage = iota
+	Footage35mm3Perf
+	Footage35mm2Perf
+	Footage16mm
+)
+
+// footageGauges holds perforations per foot and per frame.
+var footageGauges = map[Footage][2]int64{
+	Footage35mm4Perf: {64, 4},
+	Footage35mm3Perf: {64, 3},
+	Footage35mm2Perf: {64, 2},
+	Footage16mm:      {40, 1},
+}
+
+// String returns the name of the footage mode.
+func (g Footage) String() string {
+	switch g {
+	case Footage35mm4Perf:
+		return "35mm 4-perf"
+	case Footage35mm3Perf:
+		return "35mm 3-perf"
+	case Footage35mm2Perf:
+		return "35mm 2-perf"
+	case Footage16mm:
+		return "16mm"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValid indicates if g is a known footage mode.
+func (g Footage) IsValid() bool {
+	_, ok := footageGauges[g]
+	return ok
+}
+
+// FootFrame returns the first frame of foot n.
+func (g Footage) FootFrame(n int64) int64 {
+	p := footageGauges[g]
+	if p[1] == 0 {
+		return 0
+	}
+	if n < 0 {
+		return -g.FootFrame(-n)
+	}
+	// frames start at multiples of p[1] perforations
+	return (n*p[0] + p[1] - 1) / p[1]
+}
+
+// FeetFrames returns frame f as feet and frames within the foot.
+func (g Footage) FeetFrames(f int64) (int64, int64) {
+	p := footageGauges[g]
+	if p[0] == 0 {
+		return 0, 0
+	}
+	if f < 0 {
+		feet, frames := g.FeetFrames(-f)
+		return -feet, -frames
+	}
+	feet := f * p[1] / p[0]
+	return feet, f - g.FootFrame(feet)
+}
+
+// FeetFrames returns the timecode's frame as footage count 'ffff+ff'
+// from 00:00:00:00 at 0000+00. Negative frames are prefixed with '-'.
+// Unknown footage modes return an empty string.
+func (t Timecode) FeetFrames(g Footage) string {
+	if !g.IsValid() {
+		return ""
+	}
+	feet, frames := g.FeetFrames(t.Frame())
+	if feet < 0 || frames < 0 {
+		return fmt.Sprintf("-%04d+%02d", -feet, -frames)
+	}
+	return fmt.Sprintf("%04d+%02d", feet, frames)
+}
+
+// ParseFeetFrames converts the footage count s of form 'ffff+ff' in
+// footage mode g to a timecode at rate r. The frame count must be within
+// the foot.
+func ParseFeetFrames(s string, g Footage, r Rate) (Timecode, error) {
+	err := fmt.Errorf("timecode: parsing footage \"%s\": invalid syntax", s)
+	if !g.IsValid() {
+		return Invalid, fmt.Errorf("timecode: parsing footage \"%s\": invalid footage mode", s)
+	}
+	v := s
+	neg := strings.HasPrefix(v, "-")
+	if neg {
+		v = v[1:]
+	}
+	i := strings.IndexByte(v, '+')
+	if i <= 0 || i == len(v)-1 {
+		return Invalid, err
+	}
+	feet, e1 := strconv.ParseUint(v[:i], 10, 32)
+	frames, e2 := strconv.ParseUint(v[i+1:], 10, 32)
+	if e1 != nil || e2 != nil {
+		return Invalid, err
+	}
+	first := g.FootFrame(int64(feet))
+	if int64(frames) >= g.FootFrame(int64(feet)+1)-first {
+		return Invalid, fmt.Errorf("timecode: parsing footage \"%s\": frame out of range", s)
+	}
+	f := first + int64(frames)
+	if neg {
+		f = -f
+	}
+	return newTimecode(r.Duration(f), r)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"testing"
+)
+
+type FootageTestcase struct {
+	Id         string
+	Footage    Footage
+	Frame      int64
+	FeetFrames string
+}
+
+var FootageTestcases []FootageTestcase = []FootageTestcase{
+	{"4perf_0", Footage35mm4Perf, 0, "0000+00"},
+	{"4perf_15", Footage35mm4Perf, 15, "0000+15"},
+	{"4perf_16", Footage35mm4Perf, 16, "0001+00"},
+	{"4perf_1h", Footage35mm4Perf, 86400, "5400+00"},
+	{"4perf_neg", Footage35mm4Perf, -17, "-0001+01"},
+	{"3perf_21", Footage35mm3Perf, 21, "0000+21"},
+	{"3perf_22", Footage35mm3Perf, 22, "0001+00"},
+	{"3perf_42", Footage35mm3Perf, 42, "0001+20"},
+	{"3perf_43", Footage35mm3Perf, 43, "0002+00"},
+	{"3perf_63", Footage35mm3Perf, 63, "0002+20"},
+	{"3perf_64", Footage35mm3Perf, 64, "0003+00"},
+	{"3perf_86", Footage35mm3Perf, 86, "0004+00"},
+	{"3perf_1h", Footage35mm3Perf, 86400, "4050+00"},
+	{"2perf_31", Footage35mm2Perf, 31, "0000+31"},
+	{"2perf_32", Footage35mm2Perf, 32, "0001+00"},
+	{"16mm_39", Footage16mm, 39, "0000+39"},
+	{"16mm_1h", Footage16mm, 86401, "2160+01"},
+}
+
+func TestFeetFrames(t *testing.T) {
+	for _, v := range FootageTestcases {
+		tc := NewFrameCode(v.Frame, Rate24).Timecode()
+		if s := tc.FeetFrames(v.Footage); s != v.FeetFrames {
+			t.Errorf("[Case #%s] Wrong footage: expected=%s got=%s", v.Id, v.FeetFrames, s)
+		}
+		x, err := ParseFeetFrames(v.FeetFrames, v.Footage, Rate24)
+		if err != nil {
+			t.Errorf("[Case #%s] ParseFeetFrames failed: %v", v.Id, err)
+		}
+		if x != tc {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s got=%s", v.Id, tc, x)
+		}
+	}
+}
+
+func TestFeetFramesCycle(t *testing.T) {
+	// the 3-perf cycle repeats every 64 frames and 3 feet
+	for _, v := range []struct {
+		Footage Footage
+		Frames  []int64
+	}{
+		{Footage35mm4Perf, []int64{16, 16, 16}},
+		{Footage35mm3Perf, []int64{22, 21, 21}},
+		{Footage35mm2Perf, []int64{32, 32, 32}},
+		{Footage16mm, []int64{40, 40, 40}},
+	} {
+		for n := int64(0); n < 30; n++ {
+			expected := v.Frames[n%3]
+			if got := v.Footage.FootFrame(n+1) - v.Footage.FootFrame(n); got != expected {
+				t.Errorf("[Case #%s] Wrong frames in foot %d: expected=%d got=%d", v.Footage, n, expected, got)
+			}
+		}
+		// all frames roundtrip
+		for f := int64(-200); f < 2000; f++ {
+			feet, frames := v.Footage.FeetFrames(f)
+			if g := v.Footage.FootFrame(feet) + frames; g != f {
+				t.Errorf("[Case #%s] Wrong frame: expected=%d got=%d", v.Footage, f, g)
+			}
+		}
+	}
+}
+
+func TestParseFeetFramesInvalid(t *testing.T) {
+	for i, v := range []struct {
+		FeetFrames string
+		Footage    Footage
+	}{
+		{"0000+16", Footage35mm4Perf},
+		{"0000+22", Footage35mm3Perf},
+		{"0001+21", Footage35mm3Perf},
+		{"0000+40", Footage16mm},
+		{"0000", Footage35mm4Perf},
+		{"+01", Footage35mm4Perf},
+		{"0001+", Footage35mm4Perf},
+		{"00a1+01", Footage35mm4Perf},
+		{"0001+-1", Footage35mm4Perf},
+		{"0001+01", Footage(-1)},
+	} {
+		if _, err := ParseFeetFrames(v.FeetFrames, v.Footage, Rate24); err == nil {
+			t.Errorf("[Case #%d] Expected error for %s", i, v.FeetFrames)
+		}
+	}
+	if s := New(0, Rate24).FeetFrames(Footage(99)); s != "" {
+		t.Errorf("Expected empty footage, got %s", s)
+	}
+}
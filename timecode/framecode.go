@@ -0,0 +1,202 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"fmt"
+	"time"
+)
+
+// FrameCode represents a timecode as an exact frame count at an edit rate.
+// Unlike Timecode which stores nanoseconds, a FrameCode never needs rounding
+// to recover its frame number, which makes it the better choice for
+// editorial frame math at fractional rates.
+type FrameCode struct {
+	frame int64
+	rate  Rate
+}
+
+// NewFrameCode creates a new frame code from frame number f at rate r.
+func NewFrameCode(f int64, r Rate) FrameCode {
+	return FrameCode{f, r}
+}
+
+// FrameCodeFromDuration creates a new frame code from duration d at rate r.
+// The duration is rounded to the nearest frame boundary.
+func FrameCodeFromDuration(d time.Duration, r Rate) FrameCode {
+	return FrameCode{r.Frames(r.Truncate(d, 2)), r}
+}
+
+// FrameCodeFromSMPTE unpacks the SMPTE timecode from tc at rate r.
+func FrameCodeFromSMPTE(tc, bits uint32, r Rate) FrameCode {
+	return FromSMPTEAtRate(tc, bits, r).FrameCode()
+}
+
+// ParseFrameCode converts the string s to a frame code. When s contains
+// a rate after an '@' character it takes precedence, otherwise rate r
+// is used. See Parse for the accepted syntax.
+func ParseFrameCode(s string, r Rate) (FrameCode, error) {
+	t, err := Parse(s)
+	if err != nil {
+		return FrameCode{0, r}, err
+	}
+	switch t.Rate().enum {
+	case IdentityRate.enum, IdentityRateDF.enum:
+		if r.IsValid() {
+			if err := RegisterRate(r); err != nil {
+				return FrameCode{0, r}, err
+			}
+			t.SetRate(r)
+		}
+	}
+	return t.FrameCode(), nil
+}
+
+// FrameCode returns the timecode as frame code.
+func (t Timecode) FrameCode() FrameCode {
+	return FrameCode{t.Frame(), t.Rate()}
+}
+
+// Timecode returns the frame code as timecode. Frame numbers beyond the
+// timecode runtime limit are not supported.
+func (f FrameCode) Timecode() Timecode {
+	return New(f.rate.Duration(f.frame), f.rate)
+}
+
+// Frame returns the frame number.
+func (f FrameCode) Frame() int64 {
+	return f.frame
+}
+
+// FrameAtRate returns the frame number at rate r corresponding to the frame
+// code's start time.
+func (f FrameCode) FrameAtRate(r Rate) int64 {
+	if r == f.rate {
+		return f.frame
+	}
+	return r.Frames(r.Truncate(f.Duration(), 2))
+}
+
+// Rate returns the frame code's edit rate.
+func (f FrameCode) Rate() Rate {
+	return f.rate
+}
+
+// SetRate sets a new edit rate r and keeps the frame number.
+func (f *FrameCode) SetRate(r Rate) FrameCode {
+	f.rate = r
+	return *f
+}
+
+// Duration returns the realtime duration from frame zero to the frame code.
+func (f FrameCode) Duration() time.Duration {
+	return f.rate.Duration(f.frame)
+}
+
+// IsNegative indicates if the frame number is negative.
+func (f FrameCode) IsNegative() bool {
+	return f.frame < 0
+}
+
+// IsZero indicates if the frame number is zero.
+func (f FrameCode) IsZero() bool {
+	return f.frame == 0
+}
+
+// Add returns a new frame code adjusted by n frames.
+func (f FrameCode) Add(n int64) FrameCode {
+	return FrameCode{f.frame + n, f.rate}
+}
+
+// Sub returns the number of frames between f and g at f's rate.
+func (f FrameCode) Sub(g FrameCode) int64 {
+	return f.frame - g.FrameAtRate(f.rate)
+}
+
+// Compare returns -1 when f is before g, +1 when f is after g and 0 when
+// both frame codes are equal. Frame codes at different rates are compared
+// at f's rate.
+func (f FrameCode) Compare(g FrameCode) int {
+	switch n := f.Sub(g); {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SMPTE returns a packed SMPTE timecode and user bits from the current
+// frame code. Like Timecode.SMPTE() the address is wrapped at 24h.
+func (f FrameCode) SMPTE() (uint32, uint32) {
+	r := f.labelRate()
+	n := r.FramesPerDay()
+	x := f.frame % n
+	if x < 0 {
+		x += n
+	}
+	return packLabel(frameToLabel(x, r), r), 0
+}
+
+// String returns the frame code's address label as `hh:mm:ss:ff`, see
+// Timecode.String() for details.
+func (f FrameCode) String() string {
+	if f.frame < 0 {
+		return "-" + FrameCode{-f.frame, f.rate}.String()
+	}
+	r := f.labelRate()
+	return formatLabel(frameToLabel(f.frame, r), r)
+}
+
+// labelRate returns the rate used for address labels. The zero value
+// frame code has no rate and counts labels like IdentityRate.
+func (f FrameCode) labelRate() Rate {
+	if !f.rate.IsValid() {
+		return IdentityRate
+	}
+	return f.rate
+}
+
+// StringWithRate returns the frame code as string appended with the
+// rate after a separating `@` character.
+func (f FrameCode) StringWithRate() string {
+	switch {
+	case !f.rate.IsValid(), f.rate.enum == IdentityRate.enum:
+		return f.String()
+	case f.rate.IsUserDefined():
+		return fmt.Sprintf("%s@%s", f.String(), f.rate.RationalString())
+	default:
+		return fmt.Sprintf("%s@%s", f.String(), f.rate.FloatString())
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for
+// converting a frame code to string including its rate.
+func (f FrameCode) MarshalText() ([]byte, error) {
+	return []byte(f.StringWithRate()), nil
+}
+
+// UnmarshalText implements the encoding.TextMarshaler interface for
+// reading frame code values. Strings without rate keep the receiver's
+// current rate.
+func (f *FrameCode) UnmarshalText(data []byte) error {
+	x, err := ParseFrameCode(string(data), f.rate)
+	if err != nil {
+		return err
+	}
+	*f = x
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFrameCodeCreate(t *testing.T) {
+	for _, v := range TimecodeCreateTestcases {
+		r := NewRate(v.RateNum, v.RateDen)
+		f := NewFrameCode(v.Frame, r)
+		if s := f.String(); s != v.AsString {
+			t.Errorf("[Case #%s] Wrong string: expected=%s got=%s", v.Id, v.AsString, s)
+		}
+		if d := f.Duration(); d != v.Time {
+			t.Errorf("[Case #%s] Wrong duration: expected=%d got=%d", v.Id, v.Time, d)
+		}
+		if x := FrameCodeFromDuration(v.Time, r); x != f {
+			t.Errorf("[Case #%s] Wrong frame from duration: expected=%d got=%d", v.Id, f.Frame(), x.Frame())
+		}
+		v.Check(t, f.Timecode())
+		if x := f.Timecode().FrameCode(); x != f {
+			t.Errorf("[Case #%s] Wrong timecode roundtrip: expected=%d got=%d", v.Id, f.Frame(), x.Frame())
+		}
+		tc, bits := f.SMPTE()
+		if tc2, _ := f.Timecode().SMPTE(); tc != tc2 {
+			t.Errorf("[Case #%s] Wrong SMPTE: expected=%08x got=%08x", v.Id, tc2, tc)
+		}
+		if x := FrameCodeFromSMPTE(tc, bits, r); x != f {
+			t.Errorf("[Case #%s] Wrong SMPTE roundtrip: expected=%s got=%s", v.Id, f, x)
+		}
+	}
+}
+
+func TestFrameCodeParse(t *testing.T) {
+	for _, v := range TimecodeCreateTestcases {
+		r := NewRate(v.RateNum, v.RateDen)
+		f, err := ParseFrameCode(v.AsString, r)
+		if err != nil {
+			t.Errorf("[Case #%s] unexpected error: %v", v.Id, err)
+		}
+		if f.Frame() != v.Frame || f.Rate() != r {
+			t.Errorf("[Case #%s] Wrong frame: expected=%d got=%d", v.Id, v.Frame, f.Frame())
+		}
+		g, err := ParseFrameCode(f.StringWithRate(), Rate25)
+		if err != nil {
+			t.Errorf("[Case #%s] unexpected error: %v", v.Id, err)
+		}
+		if g != f {
+			t.Errorf("[Case #%s] Wrong frame with rate: expected=%s got=%s", v.Id, f.StringWithRate(), g.StringWithRate())
+		}
+	}
+}
+
+func TestFrameCodeMath(t *testing.T) {
+	// beyond the nanosecond precision limit of Timecode
+	f := NewFrameCode(1<<62, Rate23976)
+	if g := f.Add(1); g.Sub(f) != 1 || g.Compare(f) != 1 || f.Compare(g) != -1 {
+		t.Errorf("Wrong frame math at %d", f.Frame())
+	}
+	a := NewFrameCode(-36, Rate24)
+	if s := a.String(); s != "-00:00:01:12" {
+		t.Errorf("Wrong negative string: expected=-00:00:01:12 got=%s", s)
+	}
+	if x := a.Add(36); !x.IsZero() {
+		t.Errorf("Wrong zero crossing: %s", x)
+	}
+
+	// compare across rates
+	b := NewFrameCode(25, Rate25)
+	c := NewFrameCode(50, Rate50)
+	if b.Compare(c) != 0 || c.Sub(b) != 0 {
+		t.Errorf("Frame codes %s and %s should be equal", b.StringWithRate(), c.StringWithRate())
+	}
+
+	// zero value
+	var z FrameCode
+	if s := z.String(); s != Origin {
+		t.Errorf("Wrong zero value string: expected=%s got=%s", Origin, s)
+	}
+}
+
+type FrameCodeMarshal struct {
+	F FrameCode `json:"framecode"`
+}
+
+func TestFrameCodeMarshal(t *testing.T) {
+	for _, v := range TimecodeCreateTestcases {
+		m := FrameCodeMarshal{NewFrameCode(v.Frame, NewRate(v.RateNum, v.RateDen))}
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Errorf("[Case #%s] Marshal failed: %s", v.Id, err)
+		}
+		c := FrameCodeMarshal{}
+		if err = json.Unmarshal(b, &c); err != nil {
+			t.Errorf("[Case #%s] Unmarshal failed: %s", v.Id, err)
+		}
+		if c.F != m.F {
+			t.Errorf("[Case #%s] Wrong roundtrip: expected=%s got=%s", v.Id, m.F.StringWithRate(), c.F.StringWithRate())
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package ltc implements SMPTE ST 12-1 linear timecode (LTC).
+//
+// An LTC codeword has 80 bits. Bits 0-63 carry the packed timecode and user
+// bits in alternating nibbles, bits 64-79 hold the fixed sync word
+// 0011111111111101. Codewords are transmitted LSB first as biphase mark
+// audio signal: the level changes at every bit boundary and additionally in
+// the middle of every 1 bit. A polarity correction bit keeps the number of
+// zeros in each codeword even so every codeword starts with the same level.
+//
+// LTC carries one frame per codeword and is defined for rates up to 30 fps.
+package ltc
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+// SyncWord is the LTC sync word at codeword bits 64-79 with bit 64 in the
+// least significant position.
+const SyncWord uint16 = 0xBFFC
+
+// reverseSyncWord is the sync word as received when LTC is read backwards.
+const reverseSyncWord uint16 = 0x3FFD
+
+var (
+	ErrFrameRate  = errors.New("ltc: unsupported frame rate")
+	ErrSampleRate = errors.New("ltc: invalid sample rate")
+)
+
+// Word is an 80 bit LTC codeword. Bit n is stored in w[n/8] at bit n%8
+// which is the order bits are transmitted in.
+type Word [10]byte
+
+// NewWord returns the LTC codeword for packed SMPTE timecode tc and user
+// bits at rate r. The polarity correction bit is computed from the
+// codeword contents and overrides the field mark flag in tc.
+func NewWord(tc, bits uint32, r timecode.Rate) Word {
+	var w Word
+	p := timecode.InterleaveSMPTE(timecode.SMPTEFlags{FieldMark: true}.Pack(r), 0)
+	data := timecode.InterleaveSMPTE(tc, bits) &^ p
+	binary.LittleEndian.PutUint64(w[:8], data)
+	binary.LittleEndian.PutUint16(w[8:], SyncWord)
+	if w.ones()%2 > 0 {
+		binary.LittleEndian.PutUint64(w[:8], data|p)
+	}
+	return w
+}
+
+// Bit returns codeword bit n.
+func (w Word) Bit(n int) bool {
+	return w[n/8]&(1<<uint(n%8)) > 0
+}
+
+// IsValid indicates if the codeword contains the LTC sync word.
+func (w Word) IsValid() bool {
+	return binary.LittleEndian.Uint16(w[8:]) == SyncWord
+}
+
+// SMPTE returns the packed SMPTE timecode and user bits from the codeword.
+func (w Word) SMPTE() (uint32, uint32) {
+	return timecode.DeinterleaveSMPTE(binary.LittleEndian.Uint64(w[:8]))
+}
+
+// Frame returns the codeword contents as frame at rate r.
+func (w Word) Frame(r timecode.Rate) Frame {
+	tc, bits := w.SMPTE()
+	t, u, f := timecode.FromSMPTEWithFlags(tc, bits, r)
+	return Frame{
+		Timecode: t,
+		UserBits: u,
+		Flags:    f,
+	}
+}
+
+// String returns the codeword bits in transmission order.
+func (w Word) String() string {
+	b := make([]byte, 80)
+	for i := range b {
+		b[i] = '0'
+		if w.Bit(i) {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+func (w Word) ones() int {
+	n := 0
+	for _, v := range w {
+		n += bits.OnesCount8(v)
+	}
+	return n
+}
+
+// Frame is the contents of a single LTC codeword.
+type Frame struct {
+	Timecode timecode.Timecode
+	UserBits timecode.UserBits
+	// Flags holds the codeword's flag bits. LTC uses the field mark bit
+	// for polarity correction, so the decoded flag has no meaning.
+	Flags timecode.SMPTEFlags
+	// Offset is the position of the first sample of a decoded codeword in
+	// the decoder's input stream.
+	Offset int64
+	// Reverse indicates the codeword was decoded from a backwards running
+	// signal.
+	Reverse bool
+}
+
+// Word returns the frame's LTC codeword.
+func (f Frame) Word() Word {
+	tc, bits := f.Timecode.SMPTEWithFlags(f.UserBits, f.Flags)
+	return NewWord(tc, bits, f.Timecode.Rate())
+}
+
+func checkRates(sampleRate int, r timecode.Rate) error {
+	if sampleRate <= 0 {
+		return ErrSampleRate
+	}
+	if !r.IsValid() || r.Float() > 30 {
+		return ErrFrameRate
+	}
+	return nil
+}
+
+// Encoder writes LTC codewords as biphase mark modulated PCM samples. The
+// encoder keeps track of sample positions, so codewords at fractional
+// frame rates start at exact sample boundaries on average.
+type Encoder struct {
+	// Volume is the peak sample amplitude in range 0..1, default 0.5.
+	Volume float32
+
+	rate  uint64 // samples per second times rate denominator
+	div   uint64 // half bits per second times rate numerator
+	level bool
+	half  uint64 // half bits written
+	pos   uint64 // samples written
+}
+
+// NewEncoder creates a new LTC encoder for sample rate sampleRate and
+// frame rate r.
+func NewEncoder(sampleRate int, r timecode.Rate) (*Encoder, error) {
+	if err := checkRates(sampleRate, r); err != nil {
+		return nil, err
+	}
+	num, den := r.Fraction()
+	return &Encoder{
+		Volume: 0.5,
+		rate:   uint64(sampleRate) * uint64(den),
+		div:    uint64(num) * 160,
+	}, nil
+}
+
+// Offset returns the sample position at which the next codeword starts.
+func (e *Encoder) Offset() int64 {
+	return int64(e.pos)
+}
+
+// AppendFloat32 appends frame f as PCM samples to dst.
+func (e *Encoder) AppendFloat32(dst []float32, f Frame) []float32 {
+	e.encode(f.Word(), func(n int, high bool) {
+		v := -e.Volume
+		if high {
+			v = e.Volume
+		}
+		for i := 0; i < n; i++ {
+			dst = append(dst, v)
+		}
+	})
+	return dst
+}
+
+// AppendInt16 appends frame f as 16 bit PCM samples to dst.
+func (e *Encoder) AppendInt16(dst []int16, f Frame) []int16 {
+	e.encode(f.Word(), func(n int, high bool) {
+		v := int16(-e.Volume * math.MaxInt16)
+		if high {
+			v = -v
+		}
+		for i := 0; i < n; i++ {
+			dst = append(dst, v)
+		}
+	})
+	return dst
+}
+
+// encode calls emit with the length in samples and the signal level of
+// each half bit in codeword w.
+func (e *Encoder) encode(w Word, emit func(int, bool)) {
+	for i := 0; i < 80; i++ {
+		e.level = !e.level
+		emit(e.next(), e.level)
+		if w.Bit(i) {
+			e.level = !e.level
+		}
+		emit(e.next(), e.level)
+	}
+}
+
+// next returns the number of samples in the next half bit.
+func (e *Encoder) next() int {
+	e.half++
+	hi, lo := bits.Mul64(e.half, e.rate)
+	end, _ := bits.Div64(hi, lo, e.div)
+	n := end - e.pos
+	e.pos = end
+	return int(n)
+}
+
+// Decoder reads LTC codewords from biphase mark modulated PCM samples.
+// The decoder follows changes in signal level and playback speed and
+// detects codewords played forward and backwards.
+type Decoder struct {
+	rate   timecode.Rate
+	period float64 // bit length in samples
+	pos    int64   // position of the next input sample
+	prev   float32 // previous input sample
+	peak   float32 // peak signal level
+	state  int     // signal polarity
+	zero   float64 // position of the most recent zero crossing
+	last   float64 // position of the most recent transition
+	half   bool    // a half bit is pending
+	start  float64 // start position of the pending bit
+	lo, hi uint64  // shift register, the newest bit is bit 15 of hi
+	n      int     // number of valid bits in the shift register
+	starts [80]float64
+}
+
+// NewDecoder creates a new LTC decoder for sample rate sampleRate and
+// nominal frame rate r.
+func NewDecoder(sampleRate int, r timecode.Rate) (*Decoder, error) {
+	if err := checkRates(sampleRate, r); err != nil {
+		return nil, err
+	}
+	num, den := r.Fraction()
+	return &Decoder{
+		rate:   r,
+		period: float64(sampleRate) * float64(den) / float64(num) / 80,
+	}, nil
+}
+
+// DecodeFloat32 decodes PCM samples and returns all frames whose codeword
+// ends within samples. Decoder state is kept between calls, so a stream
+// can be decoded in blocks of any size.
+func (d *Decoder) DecodeFloat32(samples []float32) []Frame {
+	var frames []Frame
+	for _, v := range samples {
+		frames = d.sample(v, frames)
+	}
+	return frames
+}
+
+// DecodeInt16 decodes 16 bit PCM samples, see DecodeFloat32.
+func (d *Decoder) DecodeInt16(samples []int16) []Frame {
+	var frames []Frame
+	for _, v := range samples {
+		frames = d.sample(float32(v)/-math.MinInt16, frames)
+	}
+	return frames
+}
+
+// sample processes a single input sample. Transitions are detected with a
+// hysteresis of a quarter of the peak level and are positioned at the
+// interpolated zero crossing.
+func (d *Decoder) sample(v float32, frames []Frame) []Frame {
+	x := d.pos
+	d.pos++
+	if (v >= 0) != (d.prev >= 0) {
+		d.zero = float64(x-1) + float64(d.prev)/float64(d.prev-v)
+	}
+	d.prev = v
+	if a := float32(math.Abs(float64(v))); a > d.peak {
+		d.peak = a
+	} else {
+		d.peak *= 0.9995
+	}
+	thr := d.peak / 4
+	if thr < 1e-3 {
+		thr = 1e-3
+	}
+	s := d.state
+	switch {
+	case v > thr:
+		s = 1
+	case v < -thr:
+		s = -1
+	}
+	if s != d.state {
+		if d.state != 0 {
+			frames = d.transition(d.zero, frames)
+		}
+		d.state = s
+	}
+	return frames
+}
+
+// transition classifies the interval since the previous transition as
+// full or half bit.
+func (d *Decoder) transition(t float64, frames []Frame) []Frame {
+	last := d.last
+	dt := t - last
+	d.last = t
+	switch {
+	case dt < d.period/4 || dt > d.period*3/2:
+		// glitch or dropout, wait for the next sync word
+		d.half = false
+		d.n = 0
+	case dt < d.period*3/4:
+		// a 1 bit is accepted at its mid transition, so a codeword is
+		// complete without waiting for the next codeword's first edge
+		if !d.half {
+			d.half = true
+			d.start = last
+			frames = d.bit(1, last, frames)
+			break
+		}
+		d.half = false
+		d.period = (3*d.period + t - d.start) / 4
+	default:
+		if d.half {
+			// half bits were paired wrong, bits received so far are invalid
+			d.half = false
+			d.n = 0
+		}
+		d.period = (3*d.period + dt) / 4
+		frames = d.bit(0, last, frames)
+	}
+	return frames
+}
+
+// bit shifts bit b starting at position start into the shift register and
+// checks for a complete codeword.
+func (d *Decoder) bit(b uint64, start float64, frames []Frame) []Frame {
+	d.lo = d.lo>>1 | (d.hi&1)<<63
+	d.hi = d.hi>>1 | b<<15
+	d.starts[d.n%80] = start
+	d.n++
+	if d.n < 80 {
+		return frames
+	}
+	var (
+		w       Word
+		reverse bool
+	)
+	switch {
+	case uint16(d.hi) == SyncWord:
+		binary.LittleEndian.PutUint64(w[:8], d.lo)
+	case uint16(d.lo) == reverseSyncWord:
+		binary.LittleEndian.PutUint64(w[:8], bits.Reverse64(d.lo>>16|d.hi<<48))
+		reverse = true
+	default:
+		return frames
+	}
+	binary.LittleEndian.PutUint16(w[8:], SyncWord)
+	f := w.Frame(d.rate)
+	f.Offset = int64(math.Floor(d.starts[d.n%80])) + 1
+	f.Reverse = reverse
+	d.n = 0
+	return append(frames, f)
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package ltc
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+type LTCTestcase struct {
+	Name       string
+	SampleRate int
+	Rate       timecode.Rate
+	Start      string
+}
+
+var LTCTestcases []LTCTestcase = []LTCTestcase{
+	{"23_976", 48000, timecode.Rate23976, "00:59:59:20"},
+	{"24", 48000, timecode.Rate24, "00:59:59:20"},
+	{"25", 44100, timecode.Rate25, "00:59:59:20"},
+	{"29_97DF", 48000, timecode.Rate30DF, "00:09:59;25"},
+	{"29_97NDF", 48000, timecode.Rate30DF, "00:00:59:25"},
+	{"30", 96000, timecode.Rate30, "23:59:59:25"},
+	{"30_8k", 8000, timecode.Rate30, "10:00:00:00"},
+}
+
+// encode returns n consecutive frames starting at tc.Start and their PCM
+// samples. The frames are surrounded by silence.
+func encode(t *testing.T, v LTCTestcase, n int) ([]Frame, []float32) {
+	e, err := NewEncoder(v.SampleRate, v.Rate)
+	if err != nil {
+		t.Fatalf("[Case #%s] NewEncoder failed: %v", v.Name, err)
+	}
+	start, err := timecode.Parse(v.Start + "@" + v.Rate.FloatString())
+	if err != nil {
+		t.Fatalf("[Case #%s] Parse failed: %v", v.Name, err)
+	}
+	samples := make([]float32, 100)
+	frames := make([]Frame, n)
+	for i := range frames {
+		frames[i] = Frame{
+			Timecode: start.AddFrames(int64(i)),
+			UserBits: timecode.CharUserBits("LTC"),
+			Offset:   e.Offset() + 100,
+		}
+		samples = e.AppendFloat32(samples, frames[i])
+	}
+	return frames, append(samples, make([]float32, 100)...)
+}
+
+// align returns the expected frames starting at the first decoded frame.
+// Codewords at the start and end of a stream have no leading or trailing
+// transition and may be missed. The expected timecodes are wrapped at 24h
+// like LTC addresses.
+func align(t *testing.T, name string, expected, got []Frame) []Frame {
+	if len(got) < len(expected)-2 {
+		t.Fatalf("[Case #%s] Wrong number of frames: expected=%d got=%d", name, len(expected), len(got))
+	}
+	x := make([]Frame, 0, len(expected))
+	for _, f := range expected {
+		f.Timecode, _ = f.Timecode.Wrap()
+		if len(x) > 0 || f.Timecode == got[0].Timecode {
+			x = append(x, f)
+		}
+	}
+	if len(x) < len(got) {
+		t.Fatalf("[Case #%s] First frame %s not found", name, got[0].Timecode)
+	}
+	return x
+}
+
+func checkFrames(t *testing.T, name string, expected, got []Frame, reverse bool) {
+	expected = align(t, name, expected, got)
+	for i, g := range got {
+		x := expected[i]
+		if g.Timecode != x.Timecode {
+			t.Errorf("[Case #%s] Frame %d: wrong timecode: expected=%s got=%s", name, i, x.Timecode, g.Timecode)
+		}
+		if g.UserBits != x.UserBits {
+			t.Errorf("[Case #%s] Frame %d: wrong user bits: expected=%08x got=%08x", name, i, uint32(x.UserBits), uint32(g.UserBits))
+		}
+		if g.Offset != x.Offset {
+			t.Errorf("[Case #%s] Frame %d: wrong offset: expected=%d got=%d", name, i, x.Offset, g.Offset)
+		}
+		if g.Reverse != reverse {
+			t.Errorf("[Case #%s] Frame %d: wrong direction: expected=%t got=%t", name, i, reverse, g.Reverse)
+		}
+	}
+}
+
+func TestWord(t *testing.T) {
+	tc := timecode.New(time.Hour+25*time.Second/2, timecode.Rate25)
+	f := Frame{Timecode: tc, UserBits: 0x12345678}
+	w := f.Word()
+	if !w.IsValid() {
+		t.Errorf("Missing sync word")
+	}
+	if s := w.String(); !strings.HasSuffix(s, "0011111111111101") {
+		t.Errorf("Wrong sync word bits: %s", s[64:])
+	}
+	if n := strings.Count(w.String(), "0"); n%2 != 0 {
+		t.Errorf("Odd number of zeros in codeword: %d", n)
+	}
+	x := w.Frame(timecode.Rate25)
+	if x.Timecode != tc || x.UserBits != f.UserBits {
+		t.Errorf("Wrong frame: expected=%s/%08x got=%s/%08x", tc, uint32(f.UserBits), x.Timecode, uint32(x.UserBits))
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	if _, err := NewEncoder(0, timecode.Rate25); err != ErrSampleRate {
+		t.Errorf("Expected sample rate error, got %v", err)
+	}
+	for _, r := range []timecode.Rate{timecode.InvalidRate, timecode.IdentityRate, timecode.Rate50} {
+		if _, err := NewEncoder(48000, r); err != ErrFrameRate {
+			t.Errorf("Expected frame rate error for %s, got %v", r.RationalString(), err)
+		}
+		if _, err := NewDecoder(48000, r); err != ErrFrameRate {
+			t.Errorf("Expected frame rate error for %s, got %v", r.RationalString(), err)
+		}
+	}
+}
+
+func TestEncoderTiming(t *testing.T) {
+	e, _ := NewEncoder(48000, timecode.Rate30DF)
+	tc := timecode.New(0, timecode.Rate30DF)
+	var samples []float32
+	for i := 0; i < 5; i++ {
+		o := len(samples)
+		samples = e.AppendFloat32(samples, Frame{Timecode: tc.AddFrames(int64(i))})
+		if samples[o] != samples[0] {
+			t.Errorf("Codeword %d starts with wrong polarity", i)
+		}
+	}
+	// 5 frames at 29.97 fps are 8008 samples at 48kHz
+	if len(samples) != 8008 || e.Offset() != 8008 {
+		t.Errorf("Wrong number of samples: expected=8008 got=%d", len(samples))
+	}
+}
+
+func TestDecodeFloat32(t *testing.T) {
+	for _, v := range LTCTestcases {
+		frames, samples := encode(t, v, 40)
+		d, err := NewDecoder(v.SampleRate, v.Rate)
+		if err != nil {
+			t.Fatalf("[Case #%s] NewDecoder failed: %v", v.Name, err)
+		}
+		// decode in blocks of odd size
+		var got []Frame
+		for i := 0; i < len(samples); i += 777 {
+			j := i + 777
+			if j > len(samples) {
+				j = len(samples)
+			}
+			got = append(got, d.DecodeFloat32(samples[i:j])...)
+		}
+		checkFrames(t, v.Name, frames, got, false)
+	}
+}
+
+func TestDecodeInt16(t *testing.T) {
+	for _, v := range LTCTestcases {
+		frames, samples := encode(t, v, 10)
+		pcm := make([]int16, len(samples))
+		for i, s := range samples {
+			pcm[i] = int16(s * 32767)
+		}
+		d, _ := NewDecoder(v.SampleRate, v.Rate)
+		checkFrames(t, v.Name, frames, d.DecodeInt16(pcm), false)
+	}
+}
+
+func TestDecodeReverse(t *testing.T) {
+	for _, v := range LTCTestcases {
+		frames, samples := encode(t, v, 10)
+		n := len(samples)
+		for i := 0; i < n/2; i++ {
+			samples[i], samples[n-1-i] = samples[n-1-i], samples[i]
+		}
+		// reverse expected frames and compute their start in the reversed stream
+		e, _ := NewEncoder(v.SampleRate, v.Rate)
+		ends := make([]int64, len(frames))
+		for i := range frames {
+			e.AppendFloat32(nil, frames[i])
+			ends[i] = e.Offset() + 100
+		}
+		rev := make([]Frame, len(frames))
+		for i := range frames {
+			rev[len(frames)-1-i] = frames[i]
+			rev[len(frames)-1-i].Offset = int64(n) - ends[i]
+		}
+		d, _ := NewDecoder(v.SampleRate, v.Rate)
+		checkFrames(t, v.Name, rev, d.DecodeFloat32(samples), true)
+	}
+}
+
+func TestDecodeDistorted(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, v := range LTCTestcases {
+		frames, samples := encode(t, v, 20)
+		// inverted, attenuated and noisy signal
+		for i := range samples {
+			samples[i] = -samples[i]/5 + float32(rnd.NormFloat64()*0.005)
+		}
+		d, _ := NewDecoder(v.SampleRate, v.Rate)
+		got := d.DecodeFloat32(samples)
+		frames = align(t, v.Name, frames, got)
+		for i, g := range got {
+			x := frames[i]
+			if g.Timecode != x.Timecode {
+				t.Errorf("[Case #%s] Frame %d: wrong timecode: expected=%s got=%s", v.Name, i, x.Timecode, g.Timecode)
+			}
+			// the leading edge of the first codeword rises from noise
+			if diff := g.Offset - x.Offset; i > 0 && (diff < -1 || diff > 1) {
+				t.Errorf("[Case #%s] Frame %d: wrong offset: expected=%d got=%d", v.Name, i, x.Offset, g.Offset)
+			}
+		}
+	}
+}
+
+func TestDecodeVarispeed(t *testing.T) {
+	// LTC encoded at 48kHz, decoded with 10% speed deviation
+	for _, sr := range []int{44100, 52800} {
+		v := LTCTestcase{"varispeed", 48000, timecode.Rate25, "01:00:00:00"}
+		frames, samples := encode(t, v, 20)
+		d, _ := NewDecoder(sr, v.Rate)
+		checkFrames(t, v.Name, frames, d.DecodeFloat32(samples), false)
+	}
+}
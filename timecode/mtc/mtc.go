@@ -0,0 +1,242 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mtc implements MIDI Time Code (MTC) messages.
+//
+// MTC sends a timecode either as single full frame SysEx message
+//
+//	F0 7F <device> 01 01 hh mm ss ff F7
+//
+// or distributed over 8 quarter frame messages F1 nd. Each quarter frame
+// carries piece number n and a 4 bit data nibble d of the binary address
+// fields:
+//
+//	0  frames low nibble    4  minutes low nibble
+//	1  frames high bit      5  minutes high bits
+//	2  seconds low nibble   6  hours low nibble
+//	3  seconds high bits    7  hours high bit and rate code
+//
+// Quarter frames are sent at 4 messages per frame, so a complete sequence
+// spans 2 frames and describes the frame at which its first message was
+// sent. When running backwards, pieces are sent in reverse order.
+//
+// The 2 bit rate code in the hours field selects 24 fps (0), 25 fps (1),
+// 29.97 fps drop-frame (2) or 30 fps (3).
+package mtc
+
+import (
+	"errors"
+	"math"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+const (
+	QuarterFrame byte = 0xF1
+	SysExStart   byte = 0xF0
+	SysExEnd     byte = 0xF7
+	// AllCall is the device ID addressing all devices.
+	AllCall byte = 0x7F
+)
+
+// Latency is the number of frames between the timecode of a complete
+// quarter frame sequence and the frame at which the sequence completes.
+const Latency = 2
+
+var (
+	ErrRate    = errors.New("mtc: unsupported rate")
+	ErrMessage = errors.New("mtc: invalid message")
+)
+
+// rates maps MTC rate codes to timecode rates.
+var rates = [4]timecode.Rate{
+	timecode.Rate24,
+	timecode.Rate25,
+	timecode.Rate30DF,
+	timecode.Rate30,
+}
+
+// RateCode returns the MTC rate code for rate r. Rates with nominal frame
+// rates of 24, 25 and 30 fps are accepted, so 23.976 fps is sent with rate
+// code 0 and 29.97 fps non-drop-frame with rate code 3.
+func RateCode(r timecode.Rate) (byte, error) {
+	if !r.IsValid() {
+		return 0, ErrRate
+	}
+	switch math.Round(float64(r.Float())) {
+	case 24:
+		return 0, nil
+	case 25:
+		return 1, nil
+	case 30:
+		if r.IsDrop() {
+			return 2, nil
+		}
+		return 3, nil
+	default:
+		return 0, ErrRate
+	}
+}
+
+// CodeRate returns the timecode rate for MTC rate code c.
+func CodeRate(c byte) timecode.Rate {
+	return rates[c&3]
+}
+
+// fields returns the address fields hours, minutes, seconds and frames of
+// timecode t wrapped at 24h together with its MTC rate code.
+func fields(t timecode.Timecode) ([4]byte, byte, error) {
+	c, err := RateCode(t.Rate())
+	if err != nil {
+		return [4]byte{}, 0, err
+	}
+	tc, _ := t.SMPTE()
+	bcd := func(shift, mask uint32) byte {
+		return byte(tc>>shift&0x0F + 10*(tc>>(shift+4)&mask))
+	}
+	return [4]byte{bcd(24, 3), bcd(16, 7), bcd(8, 7), bcd(0, 3)}, c, nil
+}
+
+// makeTimecode returns the timecode for address fields hours, minutes,
+// seconds and frames at rate code c.
+func makeTimecode(f [4]byte, c byte) (timecode.Timecode, error) {
+	r := CodeRate(c)
+	if f[0] > 23 || f[1] > 59 || f[2] > 59 || float32(f[3]) >= r.Float() {
+		return timecode.Timecode(0), ErrMessage
+	}
+	bcd := func(v byte, shift uint32) uint32 {
+		return uint32(v%10)<<shift | uint32(v/10)<<(shift+4)
+	}
+	tc := bcd(f[0], 24) | bcd(f[1], 16) | bcd(f[2], 8) | bcd(f[3], 0)
+	if r.IsDrop() {
+		tc |= 1 << 6 // drop-frame flag
+	}
+	return timecode.FromSMPTEAtRate(tc, 0, r), nil
+}
+
+// QuarterFrames returns the data bytes of the 8 quarter frame messages
+// for timecode t in the order they are sent when running forward.
+func QuarterFrames(t timecode.Timecode) ([8]byte, error) {
+	var q [8]byte
+	f, c, err := fields(t)
+	if err != nil {
+		return q, err
+	}
+	for i := range q {
+		v := f[3-i/2]
+		if i%2 == 0 {
+			v &= 0x0F
+		} else {
+			v >>= 4
+		}
+		q[i] = byte(i)<<4 | v
+	}
+	q[7] |= c << 1
+	return q, nil
+}
+
+// FullFrame returns the full frame SysEx message for timecode t sent to
+// device id dev.
+func FullFrame(t timecode.Timecode, dev byte) ([]byte, error) {
+	f, c, err := fields(t)
+	if err != nil {
+		return nil, err
+	}
+	return []byte{
+		SysExStart, 0x7F, dev & 0x7F, 0x01, 0x01,
+		f[0] | c<<5, f[1], f[2], f[3],
+		SysExEnd,
+	}, nil
+}
+
+// ParseFullFrame returns the timecode and device id from full frame SysEx
+// message msg.
+func ParseFullFrame(msg []byte) (timecode.Timecode, byte, error) {
+	if len(msg) != 10 || msg[0] != SysExStart || msg[1] != 0x7F ||
+		msg[3] != 0x01 || msg[4] != 0x01 || msg[9] != SysExEnd {
+		return timecode.Timecode(0), 0, ErrMessage
+	}
+	t, err := makeTimecode([4]byte{msg[5] & 0x1F, msg[6], msg[7], msg[8]}, msg[5]>>5)
+	return t, msg[2], err
+}
+
+// Position is a timecode assembled from a quarter frame sequence.
+type Position struct {
+	// Timecode is the timecode transmitted by the sequence.
+	Timecode timecode.Timecode
+	// Current is the timecode at the time the sequence was completed,
+	// i.e. Timecode adjusted by Latency frames in the direction of motion.
+	Current timecode.Timecode
+	// Reverse indicates a sequence received in reverse order.
+	Reverse bool
+}
+
+// Assembler reconstructs timecodes from quarter frame messages.
+//
+// A timecode is complete after 8 consecutive pieces were received in
+// the same direction, which is piece 7 when running forward and piece 0
+// when running backwards. A direction change or a missing piece restarts
+// assembly. The zero value is ready to use.
+type Assembler struct {
+	data  [8]byte
+	last  int // last piece number
+	dir   int // +1 forward, -1 backwards, 0 unknown
+	count int // consecutive pieces in direction dir, 0 after reset
+}
+
+// Reset discards all received pieces, e.g. after a full frame message.
+func (a *Assembler) Reset() {
+	*a = Assembler{}
+}
+
+// Feed processes the data byte of a quarter frame message. It returns the
+// assembled position and true when the message completes a sequence.
+func (a *Assembler) Feed(data byte) (Position, bool) {
+	n := int(data>>4) & 7
+	a.data[n] = data & 0x0F
+	dir := 0
+	switch {
+	case a.count == 0:
+	case n == (a.last+1)%8:
+		dir = 1
+	case n == (a.last+7)%8:
+		dir = -1
+	}
+	switch {
+	case dir == 0:
+		a.dir, a.count = 0, 1
+	case a.dir == 0:
+		a.dir, a.count = dir, 2
+	case dir != a.dir:
+		a.dir, a.count = dir, 1
+	default:
+		a.count++
+	}
+	a.last = n
+	if a.count < 8 || (a.dir > 0 && n != 7) || (a.dir < 0 && n != 0) {
+		return Position{}, false
+	}
+	var f [4]byte
+	for i := range f {
+		f[3-i] = a.data[2*i] | a.data[2*i+1]&7<<4
+	}
+	f[0] &= 0x1F
+	t, err := makeTimecode(f, a.data[7]>>1&3)
+	if err != nil {
+		return Position{}, false
+	}
+	p := Position{Timecode: t, Reverse: a.dir < 0}
+	p.Current, _ = t.AddFramesWrap(int64(a.dir * Latency))
+	return p, true
+}
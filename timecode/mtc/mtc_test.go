@@ -0,0 +1,193 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mtc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+func parse(t *testing.T, s string, r timecode.Rate) timecode.Timecode {
+	tc, err := timecode.Parse(s + "@" + r.FloatString())
+	if err != nil {
+		t.Fatalf("Parse %s failed: %v", s, err)
+	}
+	return tc
+}
+
+type MTCTestcase struct {
+	Name      string
+	Timecode  string
+	Rate      timecode.Rate
+	Quarter   [8]byte
+	FullFrame []byte
+}
+
+var MTCTestcases []MTCTestcase = []MTCTestcase{
+	{"24", "01:02:03:04", timecode.Rate24,
+		[8]byte{0x04, 0x10, 0x23, 0x30, 0x42, 0x50, 0x61, 0x70},
+		[]byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x01, 0x02, 0x03, 0x04, 0xF7}},
+	{"25", "23:59:59:24", timecode.Rate25,
+		[8]byte{0x08, 0x11, 0x2B, 0x33, 0x4B, 0x53, 0x67, 0x73},
+		[]byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x37, 0x3B, 0x3B, 0x18, 0xF7}},
+	{"29_97DF", "00:10:00;00", timecode.Rate30DF,
+		[8]byte{0x00, 0x10, 0x20, 0x30, 0x4A, 0x50, 0x60, 0x74},
+		[]byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x40, 0x0A, 0x00, 0x00, 0xF7}},
+	{"30", "12:34:56:29", timecode.Rate30,
+		[8]byte{0x0D, 0x11, 0x28, 0x33, 0x42, 0x52, 0x6C, 0x76},
+		[]byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x6C, 0x22, 0x38, 0x1D, 0xF7}},
+}
+
+func TestRateCode(t *testing.T) {
+	for i, r := range []timecode.Rate{timecode.Rate24, timecode.Rate25, timecode.Rate30DF, timecode.Rate30} {
+		c, err := RateCode(r)
+		if err != nil || c != byte(i) {
+			t.Errorf("Wrong rate code for %s: expected=%d got=%d (%v)", r.RationalString(), i, c, err)
+		}
+		if CodeRate(c) != r {
+			t.Errorf("Wrong rate for code %d: expected=%s got=%s", c, r.RationalString(), CodeRate(c).RationalString())
+		}
+	}
+	if c, _ := RateCode(timecode.Rate23976); c != 0 {
+		t.Errorf("Wrong rate code for 23.976: expected=0 got=%d", c)
+	}
+	if c, _ := RateCode(timecode.Rate30DF.NonDrop()); c != 3 {
+		t.Errorf("Wrong rate code for 29.97 NDF: expected=3 got=%d", c)
+	}
+	for _, r := range []timecode.Rate{timecode.InvalidRate, timecode.Rate50, timecode.Rate60DF, timecode.IdentityRate} {
+		if _, err := RateCode(r); err != ErrRate {
+			t.Errorf("Expected rate error for %s, got %v", r.RationalString(), err)
+		}
+	}
+	if _, err := QuarterFrames(timecode.New(0, timecode.Rate48)); err != ErrRate {
+		t.Errorf("Expected rate error, got %v", err)
+	}
+	if _, err := FullFrame(timecode.New(0, timecode.Rate48), AllCall); err != ErrRate {
+		t.Errorf("Expected rate error, got %v", err)
+	}
+}
+
+func TestQuarterFrames(t *testing.T) {
+	for _, v := range MTCTestcases {
+		q, err := QuarterFrames(parse(t, v.Timecode, v.Rate))
+		if err != nil {
+			t.Errorf("[Case #%s] QuarterFrames failed: %v", v.Name, err)
+		}
+		if q != v.Quarter {
+			t.Errorf("[Case #%s] Wrong quarter frames: expected=% x got=% x", v.Name, v.Quarter, q)
+		}
+	}
+}
+
+func TestFullFrame(t *testing.T) {
+	for _, v := range MTCTestcases {
+		tc := parse(t, v.Timecode, v.Rate)
+		msg, err := FullFrame(tc, AllCall)
+		if err != nil {
+			t.Errorf("[Case #%s] FullFrame failed: %v", v.Name, err)
+		}
+		if !bytes.Equal(msg, v.FullFrame) {
+			t.Errorf("[Case #%s] Wrong full frame: expected=% x got=% x", v.Name, v.FullFrame, msg)
+		}
+		x, dev, err := ParseFullFrame(msg)
+		if err != nil {
+			t.Errorf("[Case #%s] ParseFullFrame failed: %v", v.Name, err)
+		}
+		if x != tc || dev != AllCall {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s got=%s", v.Name, tc.StringWithRate(), x.StringWithRate())
+		}
+	}
+	for _, msg := range [][]byte{
+		{},
+		{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00},
+		{0xF0, 0x7E, 0x7F, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0xF7},
+		{0xF0, 0x7F, 0x7F, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0xF7},
+		{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x18, 0x00, 0x00, 0x00, 0xF7},
+		{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x20, 0x00, 0x00, 0x19, 0xF7},
+	} {
+		if _, _, err := ParseFullFrame(msg); err != ErrMessage {
+			t.Errorf("Expected message error for % x, got %v", msg, err)
+		}
+	}
+}
+
+// feed sends quarter frames for n sequences starting at tc in direction
+// dir and returns all assembled positions.
+func feed(t *testing.T, a *Assembler, tc timecode.Timecode, n, dir int) []Position {
+	var p []Position
+	for i := 0; i < n; i++ {
+		q, err := QuarterFrames(tc.AddFrames(int64(2 * dir * i)))
+		if err != nil {
+			t.Fatalf("QuarterFrames failed: %v", err)
+		}
+		for j := range q {
+			if dir < 0 {
+				j = 7 - j
+			}
+			if x, ok := a.Feed(q[j]); ok {
+				p = append(p, x)
+			}
+		}
+	}
+	return p
+}
+
+func TestAssembler(t *testing.T) {
+	for _, v := range MTCTestcases {
+		for _, dir := range []int{1, -1} {
+			var a Assembler
+			tc := parse(t, v.Timecode, v.Rate)
+			p := feed(t, &a, tc, 5, dir)
+			if len(p) != 5 {
+				t.Fatalf("[Case #%s] Wrong number of positions: expected=5 got=%d", v.Name, len(p))
+			}
+			for i, x := range p {
+				exp, _ := tc.AddFramesWrap(int64(2 * dir * i))
+				cur, _ := exp.AddFramesWrap(int64(dir * Latency))
+				if x.Timecode != exp || x.Current != cur || x.Reverse != (dir < 0) {
+					t.Errorf("[Case #%s] Wrong position %d: expected=%s/%s/%t got=%s/%s/%t",
+						v.Name, i, exp, cur, dir < 0, x.Timecode, x.Current, x.Reverse)
+				}
+			}
+		}
+	}
+}
+
+func TestAssemblerDiscontinuity(t *testing.T) {
+	var a Assembler
+	tc := parse(t, "01:00:00:00", timecode.Rate25)
+	q, _ := QuarterFrames(tc)
+	// forward pieces 0-3, then backwards from piece 2
+	for _, j := range []int{0, 1, 2, 3, 2, 1, 0, 7, 6, 5, 4, 3, 2, 1} {
+		if _, ok := a.Feed(q[j]); ok {
+			t.Errorf("Unexpected position after direction change")
+		}
+	}
+	if p, ok := a.Feed(q[0]); !ok || p.Timecode != tc || !p.Reverse {
+		t.Errorf("Expected reverse position %s, got %s (%t)", tc, p.Timecode, ok)
+	}
+	// a missing piece restarts assembly
+	a.Reset()
+	for _, j := range []int{0, 1, 2, 4, 5, 6, 7, 0, 1, 2, 3, 4, 5, 6} {
+		if _, ok := a.Feed(q[j]); ok {
+			t.Errorf("Unexpected position after missing piece")
+		}
+	}
+	if p, ok := a.Feed(q[7]); !ok || p.Timecode != tc || p.Reverse {
+		t.Errorf("Expected forward position %s, got %s (%t)", tc, p.Timecode, ok)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mxf implements timecode structures used in MXF files.
+//
+// The SMPTE ST 331 timecode element (value 81h) is used in SDTI-CP and MXF
+// system items. Its 8 bytes hold the packed SMPTE timecode in bytes 0-3
+// followed by the binary groups in bytes 4-7, both in the bit layout of
+// SMPTE ST 12-1 with the lowest nibble first.
+//
+// The SMPTE ST 377-1 TimecodeComponent describes a continuous timecode
+// track by its start frame, rounded timecode base and drop-frame flag.
+package mxf
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+// ElementSize is the size of a SMPTE ST 331 timecode element.
+const ElementSize = 8
+
+// Local tags of TimecodeComponent properties.
+const (
+	TagDuration            uint16 = 0x0202
+	TagStartTimecode       uint16 = 0x1501
+	TagRoundedTimecodeBase uint16 = 0x1502
+	TagDropFrame           uint16 = 0x1503
+)
+
+// itemSize holds the value sizes of TimecodeComponent properties.
+var itemSize = map[uint16]int{
+	TagDuration:            8,
+	TagStartTimecode:       8,
+	TagRoundedTimecodeBase: 2,
+	TagDropFrame:           1,
+}
+
+var (
+	ErrLength = errors.New("mxf: invalid length")
+	ErrBase   = errors.New("mxf: invalid rounded timecode base")
+)
+
+// Element is the contents of a SMPTE ST 331 timecode element.
+type Element struct {
+	Timecode timecode.Timecode
+	UserBits timecode.UserBits
+	Flags    timecode.SMPTEFlags
+}
+
+// MarshalBinary returns the 8 byte timecode element.
+func (e Element) MarshalBinary() ([]byte, error) {
+	b := make([]byte, ElementSize)
+	tc, bits := e.Timecode.SMPTEWithFlags(e.UserBits, e.Flags)
+	binary.LittleEndian.PutUint32(b, tc)
+	binary.LittleEndian.PutUint32(b[4:], bits)
+	return b, nil
+}
+
+// ParseElement returns the contents of the 8 byte timecode element b at
+// rate r. The element's drop-frame flag selects the drop-frame or
+// non-drop-frame variant of r.
+func ParseElement(b []byte, r timecode.Rate) (Element, error) {
+	if len(b) != ElementSize {
+		return Element{}, ErrLength
+	}
+	tc := binary.LittleEndian.Uint32(b)
+	bits := binary.LittleEndian.Uint32(b[4:])
+	t, u, f := timecode.FromSMPTEWithFlags(tc, bits, r)
+	return Element{
+		Timecode: t,
+		UserBits: u,
+		Flags:    f,
+	}, nil
+}
+
+// TimecodeComponent holds the properties of a SMPTE ST 377-1 timecode
+// component.
+type TimecodeComponent struct {
+	// Start is the frame count of the first frame.
+	Start int64
+	// Base is the rate rounded to the nearest integer.
+	Base uint16
+	// DropFrame indicates drop-frame timecode labels.
+	DropFrame bool
+	// Duration is the component length in frames.
+	Duration int64
+}
+
+// NewTimecodeComponent creates a timecode component starting at timecode
+// t and lasting for duration frames.
+func NewTimecodeComponent(t timecode.Timecode, duration int64) TimecodeComponent {
+	r := t.Rate()
+	return TimecodeComponent{
+		Start:     t.Frame(),
+		Base:      uint16(math.Round(float64(r.Float()))),
+		DropFrame: r.IsDrop(),
+		Duration:  duration,
+	}
+}
+
+// Rate returns the component's timecode rate. Because the rounded timecode
+// base does not tell fractional from integer rates, the edit rate of the
+// component's track is used when its rounded value matches the base. Without
+// a matching edit rate, integer rates and 1001 based drop-frame rates are
+// assumed.
+func (c TimecodeComponent) Rate(editRate timecode.Rate) timecode.Rate {
+	r := editRate
+	if !r.IsValid() || math.Round(float64(r.Float())) != float64(c.Base) {
+		r = timecode.NewRate(int(c.Base), 1)
+	}
+	if c.DropFrame {
+		if d, ok := r.Drop(); ok {
+			return d
+		}
+		if d, ok := timecode.NewRate(int(c.Base)*1000, 1001).Drop(); ok {
+			return d
+		}
+	}
+	return r.NonDrop()
+}
+
+// Timecode returns the component's start timecode at the rate selected by
+// the track's edit rate, see Rate.
+func (c TimecodeComponent) Timecode(editRate timecode.Rate) (timecode.Timecode, error) {
+	if c.Base == 0 {
+		return timecode.Invalid, ErrBase
+	}
+	r := c.Rate(editRate)
+	if err := timecode.RegisterRate(r); err != nil {
+		return timecode.Invalid, err
+	}
+	return timecode.NewFrameCode(c.Start, r).Timecode(), nil
+}
+
+// MarshalBinary returns the component's duration, start timecode, rounded
+// timecode base and drop-frame properties as local set items with 2 byte
+// tags and lengths.
+func (c TimecodeComponent) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 4*4+8+8+2+1)
+	b = appendItem(b, TagDuration, 8)
+	b = appendUint64(b, uint64(c.Duration))
+	b = appendItem(b, TagStartTimecode, 8)
+	b = appendUint64(b, uint64(c.Start))
+	b = appendItem(b, TagRoundedTimecodeBase, 2)
+	b = append(b, byte(c.Base>>8), byte(c.Base))
+	b = appendItem(b, TagDropFrame, 1)
+	if c.DropFrame {
+		return append(b, 1), nil
+	}
+	return append(b, 0), nil
+}
+
+// UnmarshalBinary reads timecode component properties from local set
+// items in data. Items with other tags are ignored.
+func (c *TimecodeComponent) UnmarshalBinary(data []byte) error {
+	var x TimecodeComponent
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return ErrLength
+		}
+		tag := binary.BigEndian.Uint16(data)
+		n := int(binary.BigEndian.Uint16(data[2:]))
+		if len(data) < 4+n {
+			return ErrLength
+		}
+		v := data[4 : 4+n]
+		data = data[4+n:]
+		if s, ok := itemSize[tag]; ok && s != n {
+			return ErrLength
+		}
+		switch tag {
+		case TagDuration:
+			x.Duration = int64(binary.BigEndian.Uint64(v))
+		case TagStartTimecode:
+			x.Start = int64(binary.BigEndian.Uint64(v))
+		case TagRoundedTimecodeBase:
+			x.Base = binary.BigEndian.Uint16(v)
+		case TagDropFrame:
+			x.DropFrame = v[0] != 0
+		}
+	}
+	*c = x
+	return nil
+}
+
+func appendItem(b []byte, tag uint16, n int) []byte {
+	return append(b, byte(tag>>8), byte(tag), byte(n>>8), byte(n))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var x [8]byte
+	binary.BigEndian.PutUint64(x[:], v)
+	return append(b, x[:]...)
+}
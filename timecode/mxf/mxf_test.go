@@ -0,0 +1,181 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package mxf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+func parse(t *testing.T, s string, r timecode.Rate) timecode.Timecode {
+	tc, err := timecode.Parse(s + "@" + r.FloatString())
+	if err != nil {
+		t.Fatalf("Parse %s failed: %v", s, err)
+	}
+	return tc
+}
+
+type ElementTestcase struct {
+	Name     string
+	Timecode string
+	Rate     timecode.Rate
+	UserBits timecode.UserBits
+	Flags    timecode.SMPTEFlags
+	Bytes    []byte
+}
+
+var ElementTestcases []ElementTestcase = []ElementTestcase{
+	{"25", "10:11:12:13", timecode.Rate25, 0x87654321, timecode.SMPTEFlags{},
+		[]byte{0x13, 0x12, 0x11, 0x10, 0x21, 0x43, 0x65, 0x87}},
+	{"25_bgf", "00:00:00:00", timecode.Rate25, 0, timecode.SMPTEFlags{ColorFrame: true, BGF: timecode.UserBitsChars},
+		[]byte{0x80, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	{"29_97DF", "01:00:00;02", timecode.Rate30DF, 0, timecode.SMPTEFlags{DropFrame: true},
+		[]byte{0x42, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+	{"30", "23:59:59:29", timecode.Rate30, 0xFFFFFFFF, timecode.SMPTEFlags{},
+		[]byte{0x29, 0x59, 0x59, 0x23, 0xFF, 0xFF, 0xFF, 0xFF}},
+}
+
+func TestElement(t *testing.T) {
+	for _, v := range ElementTestcases {
+		e := Element{parse(t, v.Timecode, v.Rate), v.UserBits, v.Flags}
+		b, err := e.MarshalBinary()
+		if err != nil {
+			t.Errorf("[Case #%s] MarshalBinary failed: %v", v.Name, err)
+		}
+		if !bytes.Equal(b, v.Bytes) {
+			t.Errorf("[Case #%s] Wrong element: expected=% x got=% x", v.Name, v.Bytes, b)
+		}
+		x, err := ParseElement(b, v.Rate)
+		if err != nil {
+			t.Errorf("[Case #%s] ParseElement failed: %v", v.Name, err)
+		}
+		if x != e {
+			t.Errorf("[Case #%s] Wrong element: expected=%+v got=%+v", v.Name, e, x)
+		}
+	}
+	// the drop-frame flag selects the rate variant
+	if x, _ := ParseElement([]byte{0x02, 0, 0, 0, 0, 0, 0, 0}, timecode.Rate30DF); x.Timecode.Rate().IsDrop() {
+		t.Errorf("Expected non-drop-frame timecode, got %s", x.Timecode.StringWithRate())
+	}
+	if _, err := ParseElement(make([]byte, 7), timecode.Rate25); err != ErrLength {
+		t.Errorf("Expected length error, got %v", err)
+	}
+}
+
+type ComponentTestcase struct {
+	Name      string
+	Timecode  string
+	Rate      timecode.Rate
+	Start     int64
+	Base      uint16
+	DropFrame bool
+}
+
+var ComponentTestcases []ComponentTestcase = []ComponentTestcase{
+	{"23_976", "01:00:00:00", timecode.Rate23976, 86400, 24, false},
+	{"25", "10:00:00:00", timecode.Rate25, 900000, 25, false},
+	{"29_97DF", "01:00:00;00", timecode.Rate30DF, 107892, 30, true},
+	{"29_97NDF", "01:00:00:00", timecode.Rate30DF.NonDrop(), 108000, 30, false},
+	{"50", "00:00:00:00", timecode.Rate50, 0, 50, false},
+	{"59_94DF", "00:10:00;00", timecode.Rate60DF, 35964, 60, true},
+}
+
+func TestTimecodeComponent(t *testing.T) {
+	for _, v := range ComponentTestcases {
+		tc := timecode.NewFrameCode(v.Start, v.Rate).Timecode()
+		if tc.String() != v.Timecode {
+			t.Errorf("[Case #%s] Wrong start timecode: expected=%s got=%s", v.Name, v.Timecode, tc)
+		}
+		c := NewTimecodeComponent(tc, 100)
+		if c.Start != v.Start || c.Base != v.Base || c.DropFrame != v.DropFrame || c.Duration != 100 {
+			t.Errorf("[Case #%s] Wrong component: expected=%d/%d/%t got=%d/%d/%t", v.Name,
+				v.Start, v.Base, v.DropFrame, c.Start, c.Base, c.DropFrame)
+		}
+		// the track edit rate disambiguates fractional rates
+		x, err := c.Timecode(v.Rate.NonDrop())
+		if err != nil {
+			t.Errorf("[Case #%s] Timecode failed: %v", v.Name, err)
+		}
+		if x != tc {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s got=%s", v.Name, tc.StringWithRate(), x.StringWithRate())
+		}
+		b, err := c.MarshalBinary()
+		if err != nil {
+			t.Errorf("[Case #%s] MarshalBinary failed: %v", v.Name, err)
+		}
+		var y TimecodeComponent
+		if err := y.UnmarshalBinary(b); err != nil {
+			t.Errorf("[Case #%s] UnmarshalBinary failed: %v", v.Name, err)
+		}
+		if y != c {
+			t.Errorf("[Case #%s] Wrong component: expected=%+v got=%+v", v.Name, c, y)
+		}
+	}
+}
+
+func TestTimecodeComponentRate(t *testing.T) {
+	for i, v := range []struct {
+		Base      uint16
+		DropFrame bool
+		EditRate  timecode.Rate
+		Rate      timecode.Rate
+	}{
+		{24, false, timecode.Rate23976, timecode.Rate23976},
+		{24, false, timecode.Rate24, timecode.Rate24},
+		{24, false, timecode.InvalidRate, timecode.Rate24},
+		{25, false, timecode.Rate50, timecode.Rate25},
+		{30, true, timecode.InvalidRate, timecode.Rate30DF},
+		{30, true, timecode.Rate30, timecode.Rate30DF},
+		{30, false, timecode.Rate30DF, timecode.Rate30DF.NonDrop()},
+		{60, true, timecode.Rate5994, timecode.Rate60DF},
+		{60, false, timecode.Rate60DF, timecode.Rate5994},
+		{25, true, timecode.Rate25, timecode.Rate25},
+	} {
+		c := TimecodeComponent{Base: v.Base, DropFrame: v.DropFrame}
+		if r := c.Rate(v.EditRate); r != v.Rate {
+			t.Errorf("[Case #%d] Wrong rate: expected=%s got=%s", i, v.Rate.RationalString(), r.RationalString())
+		}
+	}
+	if _, err := (TimecodeComponent{}).Timecode(timecode.Rate25); err != ErrBase {
+		t.Errorf("Expected base error, got %v", err)
+	}
+}
+
+func TestTimecodeComponentUnmarshal(t *testing.T) {
+	// instance UID and data definition items are skipped
+	data := []byte{
+		0x3C, 0x0A, 0x00, 0x02, 0xAA, 0xBB,
+		0x15, 0x02, 0x00, 0x02, 0x00, 0x19,
+		0x15, 0x01, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0D, 0xBB, 0xA0,
+	}
+	var c TimecodeComponent
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Errorf("UnmarshalBinary failed: %v", err)
+	}
+	if c.Base != 25 || c.Start != 900000 || c.DropFrame {
+		t.Errorf("Wrong component: %+v", c)
+	}
+	for i, b := range [][]byte{
+		data[:3],
+		data[:10],
+		{0x15, 0x03, 0x00, 0x02, 0x00, 0x01},
+	} {
+		if err := c.UnmarshalBinary(b); err != ErrLength {
+			t.Errorf("[Case #%d] Expected length error, got %v", i, err)
+		}
+	}
+}
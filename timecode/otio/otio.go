@@ -0,0 +1,257 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package otio converts timecodes from and to OpenTimelineIO times.
+//
+// OpenTimelineIO stores times as RationalTime objects with a floating point
+// value counted in units of a floating point rate and ranges as TimeRange
+// objects with start time and duration. Both serialize to JSON objects
+// that carry a schema name:
+//
+//	{"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 86400.0}
+//
+// Timecode strings follow the rules of OTIO's to_timecode and from_timecode
+// functions. Drop-frame labels are used for 29.97 and 59.94 fps unless
+// forced otherwise, a semicolon in a parsed timecode selects drop-frame.
+// Non-drop-frame labels at fractional rates count frames at the nominal
+// integer rate.
+package otio
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+// Schema names of supported OTIO objects.
+const (
+	RationalTimeSchema = "RationalTime.1"
+	TimeRangeSchema    = "TimeRange.1"
+)
+
+// DropFrame selects drop-frame timecode labels, like OTIO's
+// IsDropFrameRate.
+type DropFrame int
+
+const (
+	InferFromRate DropFrame = -1
+	ForceNo       DropFrame = 0
+	ForceYes      DropFrame = 1
+)
+
+var (
+	ErrSchema   = errors.New("otio: invalid schema")
+	ErrRate     = errors.New("otio: invalid rate")
+	ErrDrop     = errors.New("otio: invalid rate for drop-frame timecode")
+	ErrNegative = errors.New("otio: negative value")
+)
+
+// RationalTime is an OTIO RationalTime.
+type RationalTime struct {
+	Value float64
+	Rate  float64
+}
+
+// TimeRange is an OTIO TimeRange.
+type TimeRange struct {
+	StartTime RationalTime
+	Duration  RationalTime
+}
+
+type rationalTimeJSON struct {
+	Schema string  `json:"OTIO_SCHEMA"`
+	Rate   float64 `json:"rate"`
+	Value  float64 `json:"value"`
+}
+
+type timeRangeJSON struct {
+	Schema    string       `json:"OTIO_SCHEMA"`
+	Duration  RationalTime `json:"duration"`
+	StartTime RationalTime `json:"start_time"`
+}
+
+// NewRationalTime returns timecode t as rational time that counts frames
+// at the timecode's rate.
+func NewRationalTime(t timecode.Timecode) RationalTime {
+	num, den := t.Rate().Fraction()
+	return RationalTime{
+		Value: float64(t.Frame()),
+		Rate:  float64(num) / float64(den),
+	}
+}
+
+// FromTimecode converts the timecode string s at rate to a rational time
+// like OTIO's from_timecode. Drop-frame timecodes must use a semicolon as
+// last separator.
+func FromTimecode(s string, rate float64) (RationalTime, error) {
+	r, err := Rate(rate, ForceNo)
+	if err != nil {
+		return RationalTime{}, err
+	}
+	if strings.Contains(s, ";") {
+		if r, err = Rate(rate, ForceYes); err != nil {
+			return RationalTime{}, err
+		}
+	}
+	f, err := timecode.ParseFrameCode(s, r)
+	if err != nil {
+		return RationalTime{}, err
+	}
+	return RationalTime{Value: float64(f.Frame()), Rate: rate}, nil
+}
+
+// Rate returns the timecode rate for OTIO rate f. Rates that are
+// integer multiples of 1/1001 use exact fractions. Drop-frame variants are
+// selected by drop.
+func Rate(f float64, drop DropFrame) (timecode.Rate, error) {
+	if !(f > 0) || math.IsInf(f, 0) {
+		return timecode.InvalidRate, ErrRate
+	}
+	var r timecode.Rate
+	switch {
+	case f == math.Trunc(f) && f <= math.MaxInt32:
+		r = timecode.NewRate(int(f), 1)
+	case isInt(f*1001) && f*1001 <= math.MaxInt32:
+		r = timecode.NewRate(int(math.Round(f*1001)), 1001)
+	default:
+		r = timecode.NewFloatRate(float32(f))
+	}
+	switch drop {
+	case ForceYes:
+		d, ok := r.Drop()
+		if !ok {
+			return timecode.InvalidRate, ErrDrop
+		}
+		return d, nil
+	case InferFromRate:
+		// OTIO infers drop-frame for 29.97 and 59.94 fps only
+		if num, den := r.Fraction(); den == 1001 && (num == 30000 || num == 60000) {
+			d, _ := r.Drop()
+			return d, nil
+		}
+	}
+	return r.NonDrop(), nil
+}
+
+// Timecode converts t to a timecode at its own rate. Values are rounded
+// to the nearest frame.
+func (t RationalTime) Timecode(drop DropFrame) (timecode.Timecode, error) {
+	r, err := Rate(t.Rate, drop)
+	if err != nil {
+		return timecode.Invalid, err
+	}
+	if err := timecode.RegisterRate(r); err != nil {
+		return timecode.Invalid, err
+	}
+	return timecode.NewFrameCode(int64(math.Round(t.Value)), r).Timecode(), nil
+}
+
+// ToTimecode returns t as timecode string at rate like OTIO's to_timecode.
+func (t RationalTime) ToTimecode(rate float64, drop DropFrame) (string, error) {
+	if t.Value < 0 {
+		return "", ErrNegative
+	}
+	tc, err := t.RescaledTo(rate).Timecode(drop)
+	if err != nil {
+		return "", err
+	}
+	return tc.String(), nil
+}
+
+// RescaledTo returns t counted in units of rate.
+func (t RationalTime) RescaledTo(rate float64) RationalTime {
+	if t.Rate == rate {
+		return t
+	}
+	return RationalTime{Value: t.Value * rate / t.Rate, Rate: rate}
+}
+
+// Add returns the sum of t and u at the rate of t.
+func (t RationalTime) Add(u RationalTime) RationalTime {
+	return RationalTime{Value: t.Value + u.RescaledTo(t.Rate).Value, Rate: t.Rate}
+}
+
+// MarshalJSON returns t as RationalTime.1 JSON object.
+func (t RationalTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rationalTimeJSON{RationalTimeSchema, t.Rate, t.Value})
+}
+
+// UnmarshalJSON reads t from a RationalTime.1 JSON object.
+func (t *RationalTime) UnmarshalJSON(data []byte) error {
+	var x rationalTimeJSON
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	if x.Schema != RationalTimeSchema {
+		return ErrSchema
+	}
+	*t = RationalTime{Value: x.Value, Rate: x.Rate}
+	return nil
+}
+
+// NewTimeRange returns the range from timecode in up to, but not including,
+// timecode out at the rate of in.
+func NewTimeRange(in, out timecode.Timecode) TimeRange {
+	start := NewRationalTime(in)
+	return TimeRange{
+		StartTime: start,
+		Duration: RationalTime{
+			Value: float64(out.FrameAtRate(in.Rate()) - in.Frame()),
+			Rate:  start.Rate,
+		},
+	}
+}
+
+// EndTimeExclusive returns the time just after the range.
+func (r TimeRange) EndTimeExclusive() RationalTime {
+	return r.StartTime.Add(r.Duration)
+}
+
+// Timecodes returns the range's start timecode and exclusive end timecode.
+func (r TimeRange) Timecodes(drop DropFrame) (timecode.Timecode, timecode.Timecode, error) {
+	in, err := r.StartTime.Timecode(drop)
+	if err != nil {
+		return timecode.Invalid, timecode.Invalid, err
+	}
+	out, err := r.EndTimeExclusive().Timecode(drop)
+	if err != nil {
+		return timecode.Invalid, timecode.Invalid, err
+	}
+	return in, out, nil
+}
+
+// MarshalJSON returns r as TimeRange.1 JSON object.
+func (r TimeRange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timeRangeJSON{TimeRangeSchema, r.Duration, r.StartTime})
+}
+
+// UnmarshalJSON reads r from a TimeRange.1 JSON object.
+func (r *TimeRange) UnmarshalJSON(data []byte) error {
+	var x timeRangeJSON
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	if x.Schema != TimeRangeSchema {
+		return ErrSchema
+	}
+	*r = TimeRange{StartTime: x.StartTime, Duration: x.Duration}
+	return nil
+}
+
+func isInt(f float64) bool {
+	return math.Abs(f-math.Round(f)) < 1e-6
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package otio
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+type OTIOTestcase struct {
+	Name     string
+	Timecode string
+	Rate     float64
+	Value    float64
+	TCRate   timecode.Rate
+}
+
+// values match opentime.from_timecode and opentime.to_timecode with
+// drop-frame inferred from rate
+var OTIOTestcases []OTIOTestcase = []OTIOTestcase{
+	{"23_976", "01:00:00:00", 24000.0 / 1001, 86400, timecode.Rate23976},
+	{"24", "01:00:00:00", 24, 86400, timecode.Rate24},
+	{"25", "10:00:00:01", 25, 900001, timecode.Rate25},
+	{"29_97DF", "01:00:00;00", 30000.0 / 1001, 107892, timecode.Rate30DF},
+	{"29_97DF_min", "00:01:00;02", 30000.0 / 1001, 1800, timecode.Rate30DF},
+	{"29_97DF_10min", "00:10:00;00", 30000.0 / 1001, 17982, timecode.Rate30DF},
+	{"30", "00:00:01:00", 30, 30, timecode.Rate30},
+	{"59_94DF", "00:01:00;04", 60000.0 / 1001, 3600, timecode.Rate60DF},
+	{"60", "23:59:59:59", 60, 5183999, timecode.Rate60},
+}
+
+func TestTimecode(t *testing.T) {
+	for _, v := range OTIOTestcases {
+		rt, err := FromTimecode(v.Timecode, v.Rate)
+		if err != nil {
+			t.Errorf("[Case #%s] FromTimecode failed: %v", v.Name, err)
+		}
+		if rt.Value != v.Value || rt.Rate != v.Rate {
+			t.Errorf("[Case #%s] Wrong rational time: expected=%v@%v got=%v@%v", v.Name, v.Value, v.Rate, rt.Value, rt.Rate)
+		}
+		s, err := rt.ToTimecode(v.Rate, InferFromRate)
+		if err != nil {
+			t.Errorf("[Case #%s] ToTimecode failed: %v", v.Name, err)
+		}
+		if s != v.Timecode {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s got=%s", v.Name, v.Timecode, s)
+		}
+		tc, err := rt.Timecode(InferFromRate)
+		if err != nil {
+			t.Errorf("[Case #%s] Timecode failed: %v", v.Name, err)
+		}
+		if tc.Rate() != v.TCRate || tc.String() != v.Timecode {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s@%s got=%s", v.Name, v.Timecode, v.TCRate.RationalString(), tc.StringWithRate())
+		}
+		if x := NewRationalTime(tc); x != rt {
+			t.Errorf("[Case #%s] Wrong rational time: expected=%+v got=%+v", v.Name, rt, x)
+		}
+	}
+}
+
+func TestDropFrame(t *testing.T) {
+	const ntsc = 30000.0 / 1001
+	rt := RationalTime{Value: 107892, Rate: ntsc}
+	for i, v := range []struct {
+		Drop     DropFrame
+		Timecode string
+	}{
+		{InferFromRate, "01:00:00;00"},
+		{ForceYes, "01:00:00;00"},
+		{ForceNo, "00:59:56:12"},
+	} {
+		if s, err := rt.ToTimecode(ntsc, v.Drop); err != nil || s != v.Timecode {
+			t.Errorf("[Case #%d] Wrong timecode: expected=%s got=%s (%v)", i, v.Timecode, s, err)
+		}
+	}
+	// non-drop-frame labels count at the nominal rate
+	if x, _ := FromTimecode("01:00:00:00", ntsc); x.Value != 108000 {
+		t.Errorf("Wrong value: expected=108000 got=%v", x.Value)
+	}
+	// 23.976 is never inferred as drop-frame
+	if r, _ := Rate(24000.0/1001, InferFromRate); r != timecode.Rate23976 {
+		t.Errorf("Wrong rate: %s", r.RationalString())
+	}
+	if _, err := rt.ToTimecode(24, ForceYes); err != ErrDrop {
+		t.Errorf("Expected drop-frame error, got %v", err)
+	}
+	if _, err := FromTimecode("01:00:00;00", 25); err != ErrDrop {
+		t.Errorf("Expected drop-frame error, got %v", err)
+	}
+	if _, err := (RationalTime{Value: -1, Rate: 24}).ToTimecode(24, InferFromRate); err != ErrNegative {
+		t.Errorf("Expected negative error, got %v", err)
+	}
+	if _, err := FromTimecode("01:00:00:00", 0); err != ErrRate {
+		t.Errorf("Expected rate error, got %v", err)
+	}
+}
+
+func TestRescale(t *testing.T) {
+	// 1h at 48 kHz is 01:00:00:00 at 24 fps
+	rt := RationalTime{Value: 3600 * 48000, Rate: 48000}
+	if s, _ := rt.ToTimecode(24, InferFromRate); s != "01:00:00:00" {
+		t.Errorf("Wrong timecode: expected=01:00:00:00 got=%s", s)
+	}
+	// 1.001 seconds are 30 frames at 29.97
+	rt = RationalTime{Value: 1.001, Rate: 1}
+	if s, _ := rt.ToTimecode(30000.0/1001, InferFromRate); s != "00:00:01;00" {
+		t.Errorf("Wrong timecode: expected=00:00:01;00 got=%s", s)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	rt := NewRationalTime(timecode.New(time.Hour, timecode.Rate24))
+	b, err := json.Marshal(rt)
+	if err != nil {
+		t.Errorf("Marshal failed: %v", err)
+	}
+	if s := string(b); s != `{"OTIO_SCHEMA":"RationalTime.1","rate":24,"value":86400}` {
+		t.Errorf("Wrong JSON: %s", s)
+	}
+	var x RationalTime
+	if err := json.Unmarshal([]byte(`{"OTIO_SCHEMA": "RationalTime.1", "rate": 29.97002997002997, "value": 107892.0}`), &x); err != nil {
+		t.Errorf("Unmarshal failed: %v", err)
+	}
+	if tc, _ := x.Timecode(InferFromRate); tc.StringWithRate() != "01:00:00;00@29.970" {
+		t.Errorf("Wrong timecode: %s", tc.StringWithRate())
+	}
+	if err := json.Unmarshal([]byte(`{"OTIO_SCHEMA": "TimeRange.1", "rate": 24, "value": 1}`), &x); err != ErrSchema {
+		t.Errorf("Expected schema error, got %v", err)
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	in := timecode.New(time.Hour, timecode.Rate30DF)
+	out := in.AddFrames(1800)
+	r := NewTimeRange(in, out)
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Errorf("Marshal failed: %v", err)
+	}
+	var x TimeRange
+	if err := json.Unmarshal(b, &x); err != nil {
+		t.Errorf("Unmarshal failed: %v", err)
+	}
+	if x != r {
+		t.Errorf("Wrong time range: expected=%+v got=%+v", r, x)
+	}
+	a, z, err := x.Timecodes(InferFromRate)
+	if err != nil || a != in || z != out {
+		t.Errorf("Wrong timecodes: expected=%s-%s got=%s-%s (%v)", in, out, a, z, err)
+	}
+	// durations at other rates are rescaled to the start time's rate
+	data := `{
+		"OTIO_SCHEMA": "TimeRange.1",
+		"duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 48000.0, "value": 96000.0},
+		"start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 25.0, "value": 90000.0}
+	}`
+	if err := json.Unmarshal([]byte(data), &x); err != nil {
+		t.Errorf("Unmarshal failed: %v", err)
+	}
+	if a, z, _ := x.Timecodes(InferFromRate); a.String() != "01:00:00:00" || z.String() != "01:00:02:00" {
+		t.Errorf("Wrong timecodes: expected=01:00:00:00-01:00:02:00 got=%s-%s", a, z)
+	}
+	if err := json.Unmarshal([]byte(`{"OTIO_SCHEMA": "RationalTime.1"}`), &x); err != ErrSchema {
+		t.Errorf("Expected schema error, got %v", err)
+	}
+}
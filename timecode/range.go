@@ -0,0 +1,262 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Timecode ranges
+//
+// A range addresses a contiguous span of frames at a single rate. Ranges
+// are half-open: the start frame is inside the range and the end frame is
+// the first frame after it, so adjacent ranges share their boundary and the
+// duration is the difference of both frame numbers. Edit decision lists use
+// this convention for record and source out points. Logging tools often
+// write the last frame inside the range instead, which Format and
+// ParseRange support with InclusiveEnd.
+
+package timecode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RangeEnd selects whether the end of a range string addresses the first
+// frame after the range, as editorial record out points do, or the last
+// frame inside the range.
+type RangeEnd int
+
+const (
+	ExclusiveEnd RangeEnd = iota
+	InclusiveEnd
+)
+
+// Range is a span of frames from Start up to, but not including, End. All
+// calculations use the rate of Start. Ranges with End at or before Start
+// are empty.
+type Range struct {
+	Start Timecode
+	End   Timecode
+}
+
+// NewRange creates a range of duration frames starting at start.
+func NewRange(start Timecode, duration int64) Range {
+	return Range{start, start.AddFrames(duration)}
+}
+
+// NewRangeInclusive creates a range from in to and including last.
+func NewRangeInclusive(in, last Timecode) Range {
+	return Range{in, last.AddFrames(1)}
+}
+
+// Rate returns the range's edit rate.
+func (r Range) Rate() Rate {
+	return r.Start.Rate()
+}
+
+// frames returns start and end frame at the range's rate.
+func (r Range) frames() (int64, int64) {
+	return r.Start.Frame(), r.End.FrameAtRate(r.Rate())
+}
+
+func (r Range) at(s, e int64) Range {
+	rate := r.Rate()
+	return Range{NewFrameCode(s, rate).Timecode(), NewFrameCode(e, rate).Timecode()}
+}
+
+// Duration returns the number of frames in the range.
+func (r Range) Duration() int64 {
+	if s, e := r.frames(); e > s {
+		return e - s
+	}
+	return 0
+}
+
+// IsEmpty indicates if the range contains no frames.
+func (r Range) IsEmpty() bool {
+	return r.Duration() == 0
+}
+
+// Last returns the last frame inside the range.
+func (r Range) Last() Timecode {
+	_, e := r.frames()
+	return NewFrameCode(e-1, r.Rate()).Timecode()
+}
+
+// Contains indicates if the frame addressed by t is inside the range.
+func (r Range) Contains(t Timecode) bool {
+	s, e := r.frames()
+	f := t.FrameAtRate(r.Rate())
+	return s <= f && f < e
+}
+
+// ContainsRange indicates if all frames of non-empty range o are inside r.
+func (r Range) ContainsRange(o Range) bool {
+	s, e := r.frames()
+	os, oe := r.other(o)
+	return os < oe && s <= os && oe <= e
+}
+
+// Overlaps indicates if r and o share at least one frame.
+func (r Range) Overlaps(o Range) bool {
+	s, e := r.frames()
+	os, oe := r.other(o)
+	return max64(s, os) < min64(e, oe)
+}
+
+// Intersect returns the frames shared by r and o. When the ranges do not
+// overlap the result is an empty range and false.
+func (r Range) Intersect(o Range) (Range, bool) {
+	s, e := r.frames()
+	os, oe := r.other(o)
+	s, e = max64(s, os), min64(e, oe)
+	if s >= e {
+		return r.at(s, s), false
+	}
+	return r.at(s, e), true
+}
+
+// Union returns the range covering r and o when both ranges overlap or
+// touch. Otherwise the union is not a single range and r is returned
+// together with false. Empty ranges do not extend the union.
+func (r Range) Union(o Range) (Range, bool) {
+	s, e := r.frames()
+	os, oe := r.other(o)
+	switch {
+	case os >= oe:
+		return r, true
+	case s >= e:
+		return r.at(os, oe), true
+	case max64(s, os) > min64(e, oe):
+		return r, false
+	}
+	return r.at(min64(s, os), max64(e, oe)), true
+}
+
+// Split cuts the range before the frame addressed by t. Timecodes outside
+// the range result in one empty range.
+func (r Range) Split(t Timecode) (Range, Range) {
+	s, e := r.frames()
+	if e < s {
+		e = s
+	}
+	f := clamp64(t.FrameAtRate(r.Rate()), s, e)
+	return r.at(s, f), r.at(f, e)
+}
+
+// Clamp returns the frame inside the range that is closest to t. Empty
+// ranges return their start.
+func (r Range) Clamp(t Timecode) Timecode {
+	s, e := r.frames()
+	if e <= s {
+		return r.at(s, s).Start
+	}
+	return NewFrameCode(clamp64(t.FrameAtRate(r.Rate()), s, e-1), r.Rate()).Timecode()
+}
+
+// other returns start and end frames of o at the rate of r.
+func (r Range) other(o Range) (int64, int64) {
+	rate := r.Rate()
+	return o.Start.FrameAtRate(rate), o.End.FrameAtRate(rate)
+}
+
+// String returns the range as 'hh:mm:ss:ff-hh:mm:ss:ff' with exclusive end.
+func (r Range) String() string {
+	return r.Format(ExclusiveEnd)
+}
+
+// Format returns the range as 'hh:mm:ss:ff-hh:mm:ss:ff' with exclusive or
+// inclusive end.
+func (r Range) Format(end RangeEnd) string {
+	last := r.End
+	if end == InclusiveEnd {
+		last = r.Last()
+	}
+	return r.Start.String() + "-" + last.String()
+}
+
+// ParseRange converts the string s of form 'hh:mm:ss:ff-hh:mm:ss:ff' with
+// exclusive or inclusive end to a range at rate r. A rate suffix after an
+// '@' character takes precedence.
+func ParseRange(s string, r Rate, end RangeEnd) (Range, error) {
+	v, rate := s, ""
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		v, rate = s[:i], s[i:]
+	}
+	// the separator is the first minus sign after the start timecode
+	i := 0
+	if len(v) > 1 {
+		i = strings.IndexByte(v[1:], '-') + 1
+	}
+	if i <= 0 || i == len(v)-1 {
+		return Range{Invalid, Invalid}, fmt.Errorf("timecode: parsing range \"%s\": invalid syntax", s)
+	}
+	var t [2]Timecode
+	for n, x := range []string{v[:i], v[i+1:]} {
+		f, err := ParseFrameCode(x+rate, r)
+		if err != nil {
+			return Range{Invalid, Invalid}, err
+		}
+		t[n] = f.Timecode()
+	}
+	if end == InclusiveEnd {
+		return NewRangeInclusive(t[0], t[1]), nil
+	}
+	return Range{t[0], t[1]}, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for
+// converting a range to string with exclusive end and rate.
+func (r Range) MarshalText() ([]byte, error) {
+	if !r.Start.IsValid() || !r.End.IsValid() {
+		return []byte{}, nil
+	}
+	rate := strings.TrimPrefix(r.Start.StringWithRate(), r.Start.String())
+	return []byte(r.String() + rate), nil
+}
+
+// UnmarshalText implements the encoding.TextMarshaler interface for
+// reading ranges. Strings without rate use the receiver's current rate.
+// Empty strings, as written for invalid ranges, yield an invalid range.
+func (r *Range) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*r = Range{Invalid, Invalid}
+		return nil
+	}
+	rate := InvalidRate
+	if r.Start.IsValid() {
+		rate = r.Rate()
+	}
+	x, err := ParseRange(string(data), rate, ExclusiveEnd)
+	if err != nil {
+		return err
+	}
+	*r = x
+	return nil
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func clamp64(v, lo, hi int64) int64 {
+	return max64(lo, min64(v, hi))
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustRange(s string, r Rate) Range {
+	x, err := ParseRange(s, r, ExclusiveEnd)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+func mustTimecode(s string, r Rate) Timecode {
+	f, err := ParseFrameCode(s, r)
+	if err != nil {
+		panic(err)
+	}
+	return f.Timecode()
+}
+
+type RangeTestcase struct {
+	Id        string
+	String    string
+	Inclusive string
+	Rate      Rate
+	Duration  int64
+}
+
+var RangeTestcases []RangeTestcase = []RangeTestcase{
+	{"25", "01:00:00:00-01:00:10:00", "01:00:00:00-01:00:09:24", Rate25, 250},
+	{"24", "00:59:59:00-01:00:01:00", "00:59:59:00-01:00:00:23", Rate24, 48},
+	{"29_97DF", "00:00:59;28-00:01:00;02", "00:00:59;28-00:00:59;29", Rate30DF, 2},
+	{"29_97DF_10min", "00:09:59;28-00:10:00;02", "00:09:59;28-00:10:00;01", Rate30DF, 4},
+	{"59_94DF", "00:00:59;58-00:01:00;04", "00:00:59;58-00:00:59;59", Rate60DF, 2},
+	{"negative", "-00:00:01:00-00:00:01:00", "-00:00:01:00-00:00:00:24", Rate25, 50},
+	{"empty", "01:00:00:00-01:00:00:00", "01:00:00:00-00:59:59:24", Rate25, 0},
+}
+
+func TestRange(t *testing.T) {
+	for _, v := range RangeTestcases {
+		r, err := ParseRange(v.String, v.Rate, ExclusiveEnd)
+		if err != nil {
+			t.Errorf("[Case #%s] ParseRange failed: %v", v.Id, err)
+			continue
+		}
+		if x := r.String(); x != v.String {
+			t.Errorf("[Case #%s] Wrong string: expected=%s got=%s", v.Id, v.String, x)
+		}
+		if x := r.Format(InclusiveEnd); x != v.Inclusive {
+			t.Errorf("[Case #%s] Wrong inclusive string: expected=%s got=%s", v.Id, v.Inclusive, x)
+		}
+		if d := r.Duration(); d != v.Duration {
+			t.Errorf("[Case #%s] Wrong duration: expected=%d got=%d", v.Id, v.Duration, d)
+		}
+		if x := NewRange(r.Start, v.Duration); x != r {
+			t.Errorf("[Case #%s] Wrong range: expected=%s got=%s", v.Id, r, x)
+		}
+		if v.Duration == 0 {
+			continue
+		}
+		x, err := ParseRange(v.Inclusive, v.Rate, InclusiveEnd)
+		if err != nil || x != r {
+			t.Errorf("[Case #%s] Wrong inclusive range: expected=%s got=%s (%v)", v.Id, r, x, err)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	r := mustRange("01:00:00:00-01:00:10:00", Rate25)
+	for i, v := range []struct {
+		Timecode string
+		Rate     Rate
+		Contains bool
+		Clamp    string
+	}{
+		{"00:59:59:24", Rate25, false, "01:00:00:00"},
+		{"01:00:00:00", Rate25, true, "01:00:00:00"},
+		{"01:00:05:00", Rate25, true, "01:00:05:00"},
+		{"01:00:09:24", Rate25, true, "01:00:09:24"},
+		{"01:00:10:00", Rate25, false, "01:00:09:24"},
+		{"01:00:09:49", Rate50, true, "01:00:09:24"},
+		{"01:00:10:00", Rate50, false, "01:00:09:24"},
+		{"-01:00:00:00", Rate25, false, "01:00:00:00"},
+	} {
+		tc := mustTimecode(v.Timecode, v.Rate)
+		if c := r.Contains(tc); c != v.Contains {
+			t.Errorf("[Case #%d] Wrong contains: expected=%t got=%t", i, v.Contains, c)
+		}
+		if x := r.Clamp(tc); x.String() != v.Clamp || x.Rate() != Rate25 {
+			t.Errorf("[Case #%d] Wrong clamp: expected=%s got=%s", i, v.Clamp, x.StringWithRate())
+		}
+	}
+	if x := r.Last(); x.String() != "01:00:09:24" {
+		t.Errorf("Wrong last frame: expected=01:00:09:24 got=%s", x)
+	}
+	if !r.ContainsRange(mustRange("01:00:00:00-01:00:10:00", Rate25)) {
+		t.Errorf("Range does not contain itself")
+	}
+	if r.ContainsRange(mustRange("01:00:05:00-01:00:10:01", Rate25)) {
+		t.Errorf("Range contains longer range")
+	}
+	e := mustRange("01:00:00:00-01:00:00:00", Rate25)
+	if !e.IsEmpty() || e.Contains(e.Start) || r.ContainsRange(e) {
+		t.Errorf("Empty range contains frames")
+	}
+	if x := e.Clamp(r.End); x != e.Start {
+		t.Errorf("Wrong clamp: expected=%s got=%s", e.Start, x)
+	}
+}
+
+func TestRangeSetOps(t *testing.T) {
+	a := mustRange("01:00:00:00-01:00:10:00", Rate25)
+	for i, v := range []struct {
+		Other     string
+		Overlaps  bool
+		Intersect string
+		Union     string
+		UnionOk   bool
+	}{
+		{"01:00:05:00-01:00:15:00", true, "01:00:05:00-01:00:10:00", "01:00:00:00-01:00:15:00", true},
+		{"00:59:55:00-01:00:05:00", true, "01:00:00:00-01:00:05:00", "00:59:55:00-01:00:10:00", true},
+		{"01:00:02:00-01:00:03:00", true, "01:00:02:00-01:00:03:00", "01:00:00:00-01:00:10:00", true},
+		{"00:59:00:00-01:01:00:00", true, "01:00:00:00-01:00:10:00", "00:59:00:00-01:01:00:00", true},
+		{"01:00:10:00-01:00:20:00", false, "01:00:10:00-01:00:10:00", "01:00:00:00-01:00:20:00", true},
+		{"00:59:50:00-01:00:00:00", false, "01:00:00:00-01:00:00:00", "00:59:50:00-01:00:10:00", true},
+		{"01:00:10:01-01:00:20:00", false, "01:00:10:01-01:00:10:01", "01:00:00:00-01:00:10:00", false},
+		{"01:00:20:00-01:00:20:00", false, "01:00:20:00-01:00:20:00", "01:00:00:00-01:00:10:00", true},
+	} {
+		b := mustRange(v.Other, Rate25)
+		if o := a.Overlaps(b); o != v.Overlaps {
+			t.Errorf("[Case #%d] Wrong overlap: expected=%t got=%t", i, v.Overlaps, o)
+		}
+		if o := b.Overlaps(a); o != v.Overlaps {
+			t.Errorf("[Case #%d] Wrong reverse overlap: expected=%t got=%t", i, v.Overlaps, o)
+		}
+		if x, ok := a.Intersect(b); x.String() != v.Intersect || ok != v.Overlaps {
+			t.Errorf("[Case #%d] Wrong intersection: expected=%s got=%s", i, v.Intersect, x)
+		}
+		if x, ok := a.Union(b); x.String() != v.Union || ok != v.UnionOk {
+			t.Errorf("[Case #%d] Wrong union: expected=%s/%t got=%s/%t", i, v.Union, v.UnionOk, x, ok)
+		}
+	}
+	// ranges at other rates are converted to the receiver's rate
+	b := mustRange("01:00:05:00-01:00:15:00", Rate50)
+	if x, ok := a.Intersect(b); !ok || x.String() != "01:00:05:00-01:00:10:00" || x.Rate() != Rate25 {
+		t.Errorf("Wrong intersection: expected=01:00:05:00-01:00:10:00@25.0 got=%s@%s", x, x.Rate().FloatString())
+	}
+}
+
+func TestRangeSplit(t *testing.T) {
+	r := mustRange("00:09:59;00-00:10:01;00", Rate30DF)
+	for i, v := range []struct {
+		Timecode string
+		Left     string
+		Right    string
+	}{
+		{"00:10:00;00", "00:09:59;00-00:10:00;00", "00:10:00;00-00:10:01;00"},
+		{"00:09:59;00", "00:09:59;00-00:09:59;00", "00:09:59;00-00:10:01;00"},
+		{"00:10:01;00", "00:09:59;00-00:10:01;00", "00:10:01;00-00:10:01;00"},
+		{"01:00:00;00", "00:09:59;00-00:10:01;00", "00:10:01;00-00:10:01;00"},
+		{"00:00:00;00", "00:09:59;00-00:09:59;00", "00:09:59;00-00:10:01;00"},
+	} {
+		l, x := r.Split(mustTimecode(v.Timecode, Rate30DF))
+		if l.String() != v.Left || x.String() != v.Right {
+			t.Errorf("[Case #%d] Wrong split: expected=%s/%s got=%s/%s", i, v.Left, v.Right, l, x)
+		}
+		if l.Duration()+x.Duration() != r.Duration() {
+			t.Errorf("[Case #%d] Wrong split duration: %d+%d", i, l.Duration(), x.Duration())
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	for i, v := range []string{
+		"",
+		"-",
+		"01:00:00:00",
+		"01:00:00:00-",
+		"-01:00:00:00",
+		"01:00:00:00--",
+		"01:00:00:xx-01:00:01:00",
+		"01:00:00:00-01:00:01:00@x",
+	} {
+		if _, err := ParseRange(v, Rate25, ExclusiveEnd); err == nil {
+			t.Errorf("[Case #%d] Expected error for %s", i, v)
+		}
+	}
+}
+
+func TestRangeMarshal(t *testing.T) {
+	for i, v := range []struct {
+		Range string
+		Rate  Rate
+		Text  string
+	}{
+		{"01:00:00:00-01:00:10:00", Rate25, "01:00:00:00-01:00:10:00@25.0"},
+		{"01:00:00;00-01:00:10;00", Rate30DF, "01:00:00;00-01:00:10;00@29.970"},
+		{"01:00:00:00-01:00:10:00", Rate30, "01:00:00:00-01:00:10:00@30.0"},
+		{"-00:00:01:00-00:00:01:00", Rate24, "-00:00:01:00-00:00:01:00@24.0"},
+	} {
+		r := mustRange(v.Range, v.Rate)
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Errorf("[Case #%d] Marshal failed: %v", i, err)
+		}
+		if s := string(b); s != `"`+v.Text+`"` {
+			t.Errorf("[Case #%d] Wrong JSON: expected=%s got=%s", i, v.Text, s)
+		}
+		var x Range
+		if err := json.Unmarshal(b, &x); err != nil {
+			t.Errorf("[Case #%d] Unmarshal failed: %v", i, err)
+		}
+		if x != r {
+			t.Errorf("[Case #%d] Wrong range: expected=%s got=%s", i, r, x)
+		}
+	}
+	// strings without rate keep the receiver's rate
+	x := Range{Start: New(0, Rate30DF)}
+	if err := x.UnmarshalText([]byte("00:01:00;02-00:01:00;04")); err != nil {
+		t.Errorf("UnmarshalText failed: %v", err)
+	}
+	if x.Rate() != Rate30DF || x.Duration() != 2 {
+		t.Errorf("Wrong range: %s@%s", x, x.Rate().FloatString())
+	}
+
+	// invalid ranges are written as empty string
+	b, err := json.Marshal(Range{Invalid, Invalid})
+	if err != nil || string(b) != `""` {
+		t.Errorf("Wrong JSON for invalid range: %s %v", b, err)
+	}
+	if err := json.Unmarshal(b, &x); err != nil {
+		t.Errorf("Unmarshal failed: %v", err)
+	}
+	if x.Start.IsValid() || x.End.IsValid() {
+		t.Errorf("Wrong range: expected invalid got=%s", x)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Range sets
+//
+// A range set indexes annotated timecode ranges such as markers, QC events
+// or log entries for fast lookup by frame. Ranges are kept in an interval
+// tree ordered by start frame where every node also tracks the largest end
+// frame of its subtree. Stabbing and overlap queries skip subtrees that end
+// before the query and run in O(log n + k) for k results. The tree is a
+// treap with deterministic priorities, so insert and delete run in O(log n)
+// on average and results are reproducible.
+//
+// All ranges in a set are compared at the set's rate. Ranges at other rates
+// are converted to frames at the set's rate when they are inserted.
+
+package timecode
+
+// RangeEntry is a range stored in a range set together with its value.
+type RangeEntry struct {
+	Range Range
+	Value interface{}
+}
+
+type rangeNode struct {
+	start, end int64
+	max        int64 // largest end frame in subtree
+	seq        uint64
+	prio       uint32
+	entry      RangeEntry
+	left       *rangeNode
+	right      *rangeNode
+}
+
+// RangeSet is an interval index over timecode ranges. Overlapping and
+// duplicate ranges are allowed. RangeSet is not safe for concurrent
+// modification.
+type RangeSet struct {
+	rate Rate
+	root *rangeNode
+	size int
+	seq  uint64
+	rand uint32
+}
+
+// NewRangeSet creates an empty range set that compares ranges at rate r.
+func NewRangeSet(r Rate) *RangeSet {
+	return &RangeSet{rate: r, rand: 2463534242}
+}
+
+// Rate returns the rate used for comparing ranges.
+func (s *RangeSet) Rate() Rate {
+	return s.rate
+}
+
+// Len returns the number of ranges in the set.
+func (s *RangeSet) Len() int {
+	return s.size
+}
+
+func (s *RangeSet) frames(r Range) (int64, int64) {
+	return r.Start.FrameAtRate(s.rate), r.End.FrameAtRate(s.rate)
+}
+
+// next returns the next priority from a xorshift generator.
+func (s *RangeSet) next() uint32 {
+	s.rand ^= s.rand << 13
+	s.rand ^= s.rand >> 17
+	s.rand ^= s.rand << 5
+	return s.rand
+}
+
+// Insert adds range r with value v to the set. Empty ranges contain no
+// frames and are ignored.
+func (s *RangeSet) Insert(r Range, v interface{}) {
+	start, end := s.frames(r)
+	if end <= start {
+		return
+	}
+	s.seq++
+	n := &rangeNode{
+		start: start,
+		end:   end,
+		max:   end,
+		seq:   s.seq,
+		prio:  s.next(),
+		entry: RangeEntry{r, v},
+	}
+	s.root = n.insertInto(s.root)
+	s.size++
+}
+
+// Delete removes one entry with the same frames as range r and value v
+// from the set and indicates whether such an entry existed. Values are
+// compared with ==. Values of types that are not comparable, such as
+// slices, maps and funcs, never match.
+func (s *RangeSet) Delete(r Range, v interface{}) bool {
+	start, end := s.frames(r)
+	var ok bool
+	s.root, ok = s.root.delete(start, end, v)
+	if ok {
+		s.size--
+	}
+	return ok
+}
+
+// Stab returns all entries that contain the frame addressed by t ordered
+// by start frame.
+func (s *RangeSet) Stab(t Timecode) []RangeEntry {
+	f := t.FrameAtRate(s.rate)
+	var list []RangeEntry
+	s.root.overlap(f, f+1, &list)
+	return list
+}
+
+// Overlapping returns all entries that share at least one frame with range
+// r ordered by start frame.
+func (s *RangeSet) Overlapping(r Range) []RangeEntry {
+	start, end := s.frames(r)
+	if end <= start {
+		return nil
+	}
+	var list []RangeEntry
+	s.root.overlap(start, end, &list)
+	return list
+}
+
+// Entries returns all entries ordered by start frame.
+func (s *RangeSet) Entries() []RangeEntry {
+	list := make([]RangeEntry, 0, s.size)
+	s.root.walk(func(n *rangeNode) {
+		list = append(list, n.entry)
+	})
+	return list
+}
+
+// Coalesce returns the frames covered by the set as a sorted list of
+// disjoint ranges at the set's rate. Overlapping and adjacent ranges are
+// merged.
+func (s *RangeSet) Coalesce() []Range {
+	var list []Range
+	var start, end int64
+	s.root.walk(func(n *rangeNode) {
+		if list == nil || n.start > end {
+			start, end = n.start, n.end
+			list = append(list, s.rangeAt(start, end))
+		} else if n.end > end {
+			end = n.end
+			list[len(list)-1] = s.rangeAt(start, end)
+		}
+	})
+	return list
+}
+
+// Gaps returns the frames within range r that are not covered by any entry
+// as a sorted list of disjoint ranges at the set's rate.
+func (s *RangeSet) Gaps(r Range) []Range {
+	start, end := s.frames(r)
+	var list []Range
+	for _, v := range s.Coalesce() {
+		if start >= end {
+			break
+		}
+		a, b := s.frames(v)
+		if b <= start {
+			continue
+		}
+		if a >= end {
+			break
+		}
+		if a > start {
+			list = append(list, s.rangeAt(start, a))
+		}
+		start = b
+	}
+	if start < end {
+		list = append(list, s.rangeAt(start, end))
+	}
+	return list
+}
+
+func (s *RangeSet) rangeAt(start, end int64) Range {
+	return Range{NewFrameCode(start, s.rate).Timecode(), NewFrameCode(end, s.rate).Timecode()}
+}
+
+// less orders nodes by start frame, end frame and insertion order.
+func (n *rangeNode) less(o *rangeNode) bool {
+	switch {
+	case n.start != o.start:
+		return n.start < o.start
+	case n.end != o.end:
+		return n.end < o.end
+	default:
+		return n.seq < o.seq
+	}
+}
+
+func (n *rangeNode) update() {
+	n.max = n.end
+	if n.left != nil && n.left.max > n.max {
+		n.max = n.left.max
+	}
+	if n.right != nil && n.right.max > n.max {
+		n.max = n.right.max
+	}
+}
+
+func (n *rangeNode) rotateRight() *rangeNode {
+	l := n.left
+	n.left, l.right = l.right, n
+	n.update()
+	l.update()
+	return l
+}
+
+func (n *rangeNode) rotateLeft() *rangeNode {
+	r := n.right
+	n.right, r.left = r.left, n
+	n.update()
+	r.update()
+	return r
+}
+
+// insertInto adds n to the tree at root and returns the new root.
+func (n *rangeNode) insertInto(root *rangeNode) *rangeNode {
+	if root == nil {
+		return n
+	}
+	if n.less(root) {
+		root.left = n.insertInto(root.left)
+		if root.left.prio > root.prio {
+			return root.rotateRight()
+		}
+	} else {
+		root.right = n.insertInto(root.right)
+		if root.right.prio > root.prio {
+			return root.rotateLeft()
+		}
+	}
+	root.update()
+	return root
+}
+
+// delete removes the first matching node in order and returns the new
+// subtree root.
+func (n *rangeNode) delete(start, end int64, v interface{}) (*rangeNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+	var ok bool
+	switch {
+	case start < n.start || start == n.start && end < n.end:
+		n.left, ok = n.left.delete(start, end, v)
+	case start > n.start || end > n.end:
+		n.right, ok = n.right.delete(start, end, v)
+	default:
+		// equal ranges are ordered by insertion and may be on either side
+		if n.left, ok = n.left.delete(start, end, v); ok {
+			break
+		}
+		if equalRangeValues(n.entry.Value, v) {
+			return mergeRangeNodes(n.left, n.right), true
+		}
+		n.right, ok = n.right.delete(start, end, v)
+	}
+	if ok {
+		n.update()
+	}
+	return n, ok
+}
+
+// equalRangeValues compares a and b like == but reports false instead of
+// panicking when their values are not comparable. This includes structs and
+// arrays of comparable type with interface fields that hold slices or maps.
+func equalRangeValues(a, b interface{}) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return a == b
+}
+
+// mergeRangeNodes joins two trees where all nodes in a order before all
+// nodes in b.
+func mergeRangeNodes(a, b *rangeNode) *rangeNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.prio > b.prio:
+		a.right = mergeRangeNodes(a.right, b)
+		a.update()
+		return a
+	default:
+		b.left = mergeRangeNodes(a, b.left)
+		b.update()
+		return b
+	}
+}
+
+// overlap appends all entries overlapping frames start to end in order.
+func (n *rangeNode) overlap(start, end int64, list *[]RangeEntry) {
+	if n == nil || n.max <= start {
+		return
+	}
+	n.left.overlap(start, end, list)
+	if n.start >= end {
+		return
+	}
+	if start < n.end {
+		*list = append(*list, n.entry)
+	}
+	n.right.overlap(start, end, list)
+}
+
+func (n *rangeNode) walk(fn func(*rangeNode)) {
+	if n == nil {
+		return
+	}
+	n.left.walk(fn)
+	fn(n)
+	n.right.walk(fn)
+}
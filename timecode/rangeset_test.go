@@ -0,0 +1,272 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func newTestRangeSet() *RangeSet {
+	s := NewRangeSet(Rate25)
+	for i, v := range []string{
+		"10:00:00:00-10:00:10:00",
+		"10:00:05:00-10:00:15:00",
+		"10:00:15:00-10:00:20:00",
+		"10:00:30:00-10:00:40:00",
+		"10:00:32:00-10:00:33:00",
+		"10:01:00:00-10:01:00:01",
+		"10:00:05:00-10:00:15:00",
+	} {
+		s.Insert(mustRange(v, Rate25), i)
+	}
+	return s
+}
+
+func rangeEntryIds(list []RangeEntry) []int {
+	ids := make([]int, len(list))
+	for i, v := range list {
+		ids[i] = v.Value.(int)
+	}
+	return ids
+}
+
+func rangeStrings(list []Range) string {
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i] = v.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func TestRangeSetStab(t *testing.T) {
+	s := newTestRangeSet()
+	for i, v := range []struct {
+		Timecode string
+		Rate     Rate
+		Ids      []int
+	}{
+		{"09:59:59:24", Rate25, []int{}},
+		{"10:00:00:00", Rate25, []int{0}},
+		{"10:00:05:00", Rate25, []int{0, 1, 6}},
+		{"10:00:09:24", Rate25, []int{0, 1, 6}},
+		{"10:00:10:00", Rate25, []int{1, 6}},
+		{"10:00:15:00", Rate25, []int{2}},
+		{"10:00:32:12", Rate25, []int{3, 4}},
+		{"10:00:32:25", Rate50, []int{3, 4}},
+		{"10:01:00:00", Rate25, []int{5}},
+		{"10:01:00:01", Rate25, []int{}},
+	} {
+		ids := rangeEntryIds(s.Stab(mustTimecode(v.Timecode, v.Rate)))
+		if len(ids) != len(v.Ids) {
+			t.Errorf("[Case #%d] Wrong entries: expected=%v got=%v", i, v.Ids, ids)
+			continue
+		}
+		for j := range ids {
+			if ids[j] != v.Ids[j] {
+				t.Errorf("[Case #%d] Wrong entries: expected=%v got=%v", i, v.Ids, ids)
+				break
+			}
+		}
+	}
+	if n := len(s.Overlapping(mustRange("10:00:12:00-10:00:31:00", Rate25))); n != 4 {
+		t.Errorf("Wrong number of overlapping entries: expected=4 got=%d", n)
+	}
+	if n := len(s.Overlapping(mustRange("10:00:20:00-10:00:30:00", Rate25))); n != 0 {
+		t.Errorf("Wrong number of overlapping entries: expected=0 got=%d", n)
+	}
+}
+
+func TestRangeSetDelete(t *testing.T) {
+	s := newTestRangeSet()
+	r := mustRange("10:00:05:00-10:00:15:00", Rate25)
+	if s.Delete(r, 0) {
+		t.Errorf("Deleted entry with wrong value")
+	}
+	if !s.Delete(r, 6) || s.Len() != 6 {
+		t.Errorf("Delete failed, len=%d", s.Len())
+	}
+	if s.Delete(r, 6) {
+		t.Errorf("Deleted entry twice")
+	}
+	if ids := rangeEntryIds(s.Stab(r.Start)); len(ids) != 2 || ids[0] != 0 || ids[1] != 1 {
+		t.Errorf("Wrong entries: expected=[0 1] got=%v", ids)
+	}
+	for i := 0; i < 6; i++ {
+		for _, v := range s.Entries() {
+			if v.Value == i && !s.Delete(v.Range, i) {
+				t.Errorf("[Case #%d] Delete failed", i)
+			}
+		}
+	}
+	if s.Len() != 0 || len(s.Entries()) != 0 {
+		t.Errorf("Set not empty, len=%d", s.Len())
+	}
+	s.Insert(mustRange("10:00:00:00-10:00:00:00", Rate25), 0)
+	if s.Len() != 0 {
+		t.Errorf("Inserted empty range")
+	}
+}
+
+func TestRangeSetDeleteNotComparable(t *testing.T) {
+	s := NewRangeSet(Rate25)
+	r := mustRange("10:00:00:00-10:00:01:00", Rate25)
+	s.Insert(r, []string{"a"})
+	s.Insert(r, map[string]int{"b": 1})
+	s.Insert(r, 1)
+	for i, v := range []interface{}{[]string{"a"}, map[string]int{"b": 1}, func() {}, nil} {
+		if s.Delete(r, v) {
+			t.Errorf("[Case #%d] Deleted entry with wrong value", i)
+		}
+	}
+	if !s.Delete(r, 1) || s.Len() != 2 {
+		t.Errorf("Delete failed, len=%d", s.Len())
+	}
+
+	// comparable types may hold values that are not comparable
+	type tagged struct {
+		Tag interface{}
+	}
+	s = NewRangeSet(Rate25)
+	s.Insert(r, tagged{[]string{"a"}})
+	s.Insert(r, [1]interface{}{map[string]int{"b": 1}})
+	s.Insert(r, tagged{"a"})
+	for i, v := range []interface{}{tagged{[]string{"a"}}, [1]interface{}{map[string]int{"b": 1}}, tagged{"b"}} {
+		if s.Delete(r, v) {
+			t.Errorf("[Case #%d] Deleted entry with wrong value", i)
+		}
+	}
+	if !s.Delete(r, tagged{"a"}) || s.Len() != 2 {
+		t.Errorf("Delete failed, len=%d", s.Len())
+	}
+}
+
+func TestRangeSetCoalesce(t *testing.T) {
+	s := newTestRangeSet()
+	expected := "10:00:00:00-10:00:20:00 10:00:30:00-10:00:40:00 10:01:00:00-10:01:00:01"
+	if x := rangeStrings(s.Coalesce()); x != expected {
+		t.Errorf("Wrong coalesced ranges: expected=%s got=%s", expected, x)
+	}
+	for i, v := range []struct {
+		Range string
+		Gaps  string
+	}{
+		{"09:59:00:00-10:02:00:00", "09:59:00:00-10:00:00:00 10:00:20:00-10:00:30:00 10:00:40:00-10:01:00:00 10:01:00:01-10:02:00:00"},
+		{"10:00:05:00-10:00:35:00", "10:00:20:00-10:00:30:00"},
+		{"10:00:32:00-10:00:33:00", ""},
+		{"10:00:45:00-10:00:50:00", "10:00:45:00-10:00:50:00"},
+		{"10:00:45:00-10:00:45:00", ""},
+	} {
+		if x := rangeStrings(s.Gaps(mustRange(v.Range, Rate25))); x != v.Gaps {
+			t.Errorf("[Case #%d] Wrong gaps: expected=%s got=%s", i, v.Gaps, x)
+		}
+	}
+	if x := NewRangeSet(Rate25).Gaps(mustRange("10:00:00:00-10:00:01:00", Rate25)); rangeStrings(x) != "10:00:00:00-10:00:01:00" {
+		t.Errorf("Wrong gaps: %s", rangeStrings(x))
+	}
+}
+
+func randomRanges(n int, seed int64) []Range {
+	rnd := rand.New(rand.NewSource(seed))
+	list := make([]Range, n)
+	for i := range list {
+		start := NewFrameCode(rnd.Int63n(int64(n)*10), Rate25).Timecode()
+		list[i] = NewRange(start, 1+rnd.Int63n(500))
+	}
+	return list
+}
+
+func TestRangeSetRandom(t *testing.T) {
+	list := randomRanges(2000, 1)
+	s := NewRangeSet(Rate25)
+	for i, r := range list {
+		s.Insert(r, i)
+	}
+	// delete every third range
+	for i := 0; i < len(list); i += 3 {
+		if !s.Delete(list[i], i) {
+			t.Errorf("[Case #%d] Delete failed", i)
+		}
+	}
+	for f := int64(0); f < 20500; f += 97 {
+		tc := NewFrameCode(f, Rate25).Timecode()
+		n := 0
+		for i, r := range list {
+			if i%3 != 0 && r.Contains(tc) {
+				n++
+			}
+		}
+		x := s.Stab(tc)
+		if len(x) != n {
+			t.Errorf("[Case #%d] Wrong number of entries: expected=%d got=%d", f, n, len(x))
+		}
+		for i, v := range x {
+			if !v.Range.Contains(tc) || i > 0 && x[i-1].Range.Start.Frame() > v.Range.Start.Frame() {
+				t.Errorf("[Case #%d] Wrong entry %s", f, v.Range)
+			}
+		}
+	}
+}
+
+func BenchmarkRangeSetInsert(b *testing.B) {
+	list := randomRanges(10000, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewRangeSet(Rate25)
+		for j, r := range list {
+			s.Insert(r, j)
+		}
+	}
+}
+
+func BenchmarkRangeSetStab(b *testing.B) {
+	list := randomRanges(10000, 1)
+	s := NewRangeSet(Rate25)
+	for j, r := range list {
+		s.Insert(r, j)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Stab(NewFrameCode(int64(i%100000), Rate25).Timecode())
+	}
+}
+
+func BenchmarkRangeSetOverlapping(b *testing.B) {
+	list := randomRanges(10000, 1)
+	s := NewRangeSet(Rate25)
+	for j, r := range list {
+		s.Insert(r, j)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Overlapping(NewRange(NewFrameCode(int64(i%100000), Rate25).Timecode(), 250))
+	}
+}
+
+func BenchmarkRangeSetDelete(b *testing.B) {
+	list := randomRanges(10000, 1)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := NewRangeSet(Rate25)
+		for j, r := range list {
+			s.Insert(r, j)
+		}
+		b.StartTimer()
+		for j, r := range list {
+			s.Delete(r, j)
+		}
+	}
+}
@@ -17,8 +17,10 @@ package timecode
 import (
 	"fmt"
 	"math"
+	"math/bits"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -57,19 +59,26 @@ const (
 	R_96           // 96,1
 	R_100          // 100,1
 	R_120          // 120,1
+	R_47952        // 48000,1001
+	R_5994         // 60000,1001
+	R_11988        // 120000,1001
+	R_2997         // 30000,1001 (non-drop-frame variant of R_30DF)
 	R_MAX   = 15   // special case: requires rateNum and rateDen to be set
 )
 
 // Standard edit rates for drop-frame timecodes.
 const (
-	df     = 16 + iota
-	_      // undefined
-	_      // undefined
-	_      // undefined
-	R_30DF // 30000,1001
-	_      // undefined
-	_      // undefined
-	R_60DF // 60000,1001
+	df      = 16 + iota
+	_       // undefined
+	_       // undefined
+	_       // undefined
+	R_30DF  // 30000,1001
+	_       // undefined
+	_       // undefined
+	R_60DF  // 60000,1001
+	_       // undefined
+	_       // undefined
+	R_120DF // 120000,1001 (ST 12-3)
 )
 
 // Common edit rate configurations you should use in your code when calling New()
@@ -90,6 +99,11 @@ var (
 	Rate96         Rate = Rate{R_96, 96, 96, 1, 0, 96 * 600}
 	Rate100        Rate = Rate{R_100, 100, 100, 1, 0, 100 * 600}
 	Rate120        Rate = Rate{R_120, 120, 120, 1, 0, 120 * 600}
+	Rate47952      Rate = Rate{R_47952, 48, 48000, 1001, 0, 48 * 600}
+	Rate5994       Rate = Rate{R_5994, 60, 60000, 1001, 0, 60 * 600}
+	Rate11988      Rate = Rate{R_11988, 120, 120000, 1001, 0, 120 * 600}
+	Rate2997       Rate = Rate{R_2997, 30, 30000, 1001, 0, 30 * 600}
+	Rate120DF      Rate = Rate{R_120DF, 120, 120000, 1001, 8, 71928}
 )
 
 var rates map[int]Rate = map[int]Rate{
@@ -107,6 +121,103 @@ var rates map[int]Rate = map[int]Rate{
 	R_96:    Rate96,
 	R_100:   Rate100,
 	R_120:   Rate120,
+	R_47952: Rate47952,
+	R_5994:  Rate5994,
+	R_11988: Rate11988,
+	R_2997:  Rate2997,
+	R_120DF: Rate120DF,
+}
+
+// drop-frame and non-drop-frame variants of the same edit rate
+var dropRates map[int]int = map[int]int{
+	R_30DF:  R_2997,
+	R_60DF:  R_5994,
+	R_120DF: R_11988,
+}
+
+// User-defined rates have no standard enum id. To keep them inside a packed
+// Timecode value they are assigned one of the unused rate ids on first use.
+// Ids are process-local and must not be persisted in binary form.
+var (
+	userRatesMu sync.RWMutex
+	userRates   map[int]Rate = make(map[int]Rate)
+)
+
+// userRate creates a user-defined rate from rate numerator n and denominator d.
+// The fraction is reduced so that equal rates share the same registry id.
+func userRate(n, d int) Rate {
+	if g := gcd(n, d); g > 1 {
+		n, d = n/g, d/g
+	}
+	fps := (n + d - 1) / d
+	return Rate{R_MAX, fps, n, d, 0, fps * 600}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// rateId returns the identifier stored in the rate bits of a packed timecode.
+// Standard rates use their enum id, user-defined rates are registered and
+// receive a free id. Ids are never released, so the registry holds only a
+// small number of distinct user-defined rates per process. When all ids are
+// in use, rateId returns an error.
+func rateId(r Rate) (int, error) {
+	if r.enum != R_MAX || !r.IsValid() {
+		return r.enum, nil
+	}
+	userRatesMu.RLock()
+	for id, v := range userRates {
+		if v.rateNum == r.rateNum && v.rateDen == r.rateDen {
+			userRatesMu.RUnlock()
+			return id, nil
+		}
+	}
+	userRatesMu.RUnlock()
+
+	userRatesMu.Lock()
+	defer userRatesMu.Unlock()
+	for id := 1; id < df|R_MAX; id++ {
+		if _, ok := rates[id]; ok || id == R_MAX {
+			continue
+		}
+		v, ok := userRates[id]
+		if !ok {
+			userRates[id] = r
+			return id, nil
+		}
+		if v.rateNum == r.rateNum && v.rateDen == r.rateDen {
+			// registered concurrently
+			return id, nil
+		}
+	}
+	return R_MAX, fmt.Errorf("timecode: no free id for user-defined rate %s", r.RationalString())
+}
+
+// RegisterRate assigns a packed rate id to user-defined rate r. New does this
+// on first use and panics when all ids are taken. Long-running programs that
+// create rates from external input should call RegisterRate first and handle
+// the error. Standard rates need no registration.
+func RegisterRate(r Rate) error {
+	_, err := rateId(r)
+	return err
+}
+
+// rateFromId returns the standard or registered user-defined rate for id.
+// Unknown ids resolve to IdentityRate.
+func rateFromId(id int) Rate {
+	if r, ok := rates[id]; ok {
+		return r
+	}
+	userRatesMu.RLock()
+	defer userRatesMu.RUnlock()
+	if r, ok := userRates[id]; ok {
+		return r
+	}
+	return IdentityRate
 }
 
 // NewRate creates a user-defined rate from rate numerator n and denominator d.
@@ -122,7 +233,7 @@ func NewRate(n, d int) Rate {
 	fps := float32(n) / float32(d)
 	r := NewFloatRate(fps)
 	if r.enum == R_MAX {
-		return Rate{R_MAX, int(math.Ceil(float64(fps))), n, d, 0, int(fps * 600)}
+		return userRate(n, d)
 	}
 	return r
 }
@@ -142,6 +253,8 @@ func NewFloatRate(f float32) Rate {
 		return rates[R_30DF]
 	case f == 30:
 		return rates[R_30]
+	case 47.95 <= f && f < 47.96:
+		return rates[R_47952]
 	case f == 48:
 		return rates[R_48]
 	case f == 50:
@@ -154,27 +267,51 @@ func NewFloatRate(f float32) Rate {
 		return rates[R_96]
 	case f == 100:
 		return rates[R_100]
+	case 119.87 < f && f < 119.89:
+		return rates[R_120DF]
 	case f == 120:
 		return rates[R_120]
 	default:
-		return Rate{R_MAX, int(f), int(f * 1000), 1000, 0, int(f) * 600}
+		return userRate(int(math.Round(float64(f)*1000)), 1000)
 	}
 }
 
 // ParseRate converts the string s to a rate. The string is treated as a
-// rate enumeration index when its value is an integer, as floating point
-// rate when s parses as float32 or as rational rate otherwise.
+// rate enumeration index when its value is the index of one of the rates
+// 0 to R_120, R_30DF or R_60DF, as floating point rate when s parses as
+// float32 or as rational rate otherwise. Rates added later, such as 59.94
+// non-drop-frame, are selected by value and 'DF' or 'NDF' suffix only.
 //
 // If the pased float or rational rate is approximately close to a pre-defined
 // standard rate, the standard rate's configuration including the appropriate
-// enum id will be used.
+// enum id will be used. Ambiguous rates like 59.94 resolve to drop-frame
+// unless s carries a 'NDF' suffix, e.g. '59.94NDF'. A 'DF' suffix selects
+// the drop-frame variant.
 func ParseRate(s string) (Rate, error) {
-	// try parsing as index
+	// strip drop-frame suffix
+	switch u := strings.ToUpper(s); {
+	case strings.HasSuffix(u, "NDF"):
+		r, err := ParseRate(s[:len(s)-3])
+		if err != nil {
+			return InvalidRate, err
+		}
+		return r.NonDrop(), nil
+	case strings.HasSuffix(u, "DF"):
+		r, err := ParseRate(s[:len(s)-2])
+		if err != nil {
+			return InvalidRate, err
+		}
+		if d, ok := r.Drop(); ok {
+			return d, nil
+		}
+		return InvalidRate, fmt.Errorf("timecode: parsing rate \"%s\": no drop-frame variant", s)
+	}
+
+	// try parsing as index; only the original standard rates have a stable
+	// index, other integers are frame rates
 	if i, err := strconv.Atoi(s); err == nil {
 		switch {
-		case i <= R_MAX:
-			fallthrough
-		case i == R_30DF || i == R_60DF:
+		case 0 <= i && i <= R_120, i == R_30DF, i == R_60DF:
 			return rates[i], nil
 		default:
 			return NewFloatRate(float32(i)), nil
@@ -188,10 +325,10 @@ func ParseRate(s string) (Rate, error) {
 
 	// try parsing as rational
 	if fields := strings.Split(s, "/"); len(fields) == 2 {
-		a, _ := strconv.Atoi(fields[0])
-		b, err := strconv.Atoi(fields[1])
-		if err == nil && b > 0 {
-			return NewFloatRate(float32(a) / float32(b)), nil
+		a, err1 := strconv.Atoi(fields[0])
+		b, err2 := strconv.Atoi(fields[1])
+		if err1 == nil && err2 == nil && a > 0 && b > 0 {
+			return NewRate(a, b), nil
 		}
 	}
 
@@ -211,11 +348,47 @@ func (r Rate) IsValid() bool {
 	return r.rateNum > 0 && r.rateDen > 0
 }
 
+// IsUserDefined indicates if the rate is a user-defined rate without a
+// standard enum id.
+func (r Rate) IsUserDefined() bool {
+	return r.enum == R_MAX
+}
+
 // IsDrop indicates if the rate refers to a drop-frame timecode.
 func (r Rate) IsDrop() bool {
 	return r.enum&0x10 > 0
 }
 
+// Drop returns the drop-frame variant of rate r. The second return value is
+// false when no such variant exists.
+func (r Rate) Drop() (Rate, bool) {
+	if r.IsDrop() {
+		return r, true
+	}
+	for id, d := range rates {
+		if id != df && d.IsDrop() && d.rateNum == r.rateNum && d.rateDen == r.rateDen {
+			return d, true
+		}
+	}
+	return r, false
+}
+
+// NonDrop returns the non-drop-frame variant of rate r. Drop-frame rates
+// without a standard non-drop-frame variant become user-defined rates that
+// count frame labels without skipping.
+func (r Rate) NonDrop() Rate {
+	if !r.IsDrop() {
+		return r
+	}
+	if n, ok := dropRates[r.enum]; ok {
+		return rates[n]
+	}
+	if r.enum == df {
+		return IdentityRate
+	}
+	return userRate(r.rateNum, r.rateDen)
+}
+
 // IndexString returns the enumeration for a standard timecode as string.
 func (r Rate) IndexString() string {
 	return strconv.Itoa(r.enum)
@@ -280,40 +453,89 @@ func (r *Rate) UnmarshalText(data []byte) error {
 	}
 }
 
-// FrameDuration returns the duration of a single frame at the edit rate.
+// FramesPerDay returns the number of frames in a 24h timecode address range
+// at the edit rate. For drop-frame rates this is the number of valid timecode
+// address labels between 00:00:00;00 and 23:59:59;ff.
+func (r Rate) FramesPerDay() int64 {
+	return 144 * int64(r.framesPer10Min)
+}
+
+// maxFrames returns the largest frame number within the timecode runtime
+// limit.
+func (r Rate) maxFrames() int64 {
+	return r.Frames(time.Duration(time_mask >> 1))
+}
+
+// FrameDuration returns the duration of a single frame at the edit rate
+// truncated to nanoseconds.
 func (r Rate) FrameDuration() time.Duration {
 	if r.rateNum == 0 {
 		return time.Nanosecond
 	}
-	return time.Duration(1000000000 * float64(r.rateDen) / float64(r.rateNum))
+	return r.Duration(1)
 }
 
-// Duration returns the duration of f frames at the edit rate.
+// Duration returns the duration of f frames at the edit rate. The result is
+// computed from the exact rational frame duration and truncated to the
+// nanosecond.
 func (r Rate) Duration(f int64) time.Duration {
-	if r.rateNum == 0 {
+	if !r.IsValid() {
 		return 0
 	}
-	d := time.Duration(float64(f) * 1000000000 * float64(r.rateDen) / float64(r.rateNum))
-	return r.Truncate(d, 2)
+	if f < 0 {
+		return -r.Duration(-f)
+	}
+	return time.Duration(mulDiv(uint64(f), nanos*uint64(r.rateDen), 0, uint64(r.rateNum)))
 }
 
 // Frames returns the number of frames matching duration d at the edit rate.
+// This is the exact inverse of Duration, i.e. the frame whose start time
+// is at or before d.
 func (r Rate) Frames(d time.Duration) int64 {
-	return int64(d / r.FrameDuration())
+	if !r.IsValid() {
+		return int64(d)
+	}
+	if d < 0 {
+		return -r.Frames(-d)
+	}
+	n := uint64(r.rateNum)
+	return int64(mulDiv(uint64(d), n, n-1, nanos*uint64(r.rateDen)))
 }
 
-// Truncate clips duration d to the edit rate's interval length, while internally
-// rounding to precision digits.
+// Truncate clips duration d to the nearest frame boundary at the edit rate.
+// The precision argument is no longer used since boundaries are computed
+// with exact rational arithmetic.
 func (r Rate) Truncate(d time.Duration, precision int) time.Duration {
-	i := int64(d)
-	n := int64(r.FrameDuration())
-	if x := i % n; x > n/2 {
-		return time.Duration(i + n - x)
-	} else if x > n/int64(precision) {
-		return time.Duration(i - x)
-	} else {
+	if !r.IsValid() {
 		return d
 	}
+	if d < 0 {
+		return -r.Truncate(-d, precision)
+	}
+	f := r.Frames(d)
+	a, b := r.Duration(f), r.Duration(f+1)
+	if d-a > (b-a)/2 {
+		return b
+	}
+	return a
+}
+
+const nanos = uint64(time.Second)
+
+// mulDiv returns (a*b+c)/d rounded down for unsigned values using 128bit
+// intermediate precision. Results that do not fit into an int64 saturate.
+func mulDiv(a, b, c, d uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	lo, carry := bits.Add64(lo, c, 0)
+	hi += carry
+	if hi >= d {
+		return math.MaxInt64
+	}
+	q, _ := bits.Div64(hi, lo, d)
+	if q > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return q
 }
 
 func (r Rate) TruncateFloat(d float64, precision int) time.Duration {
@@ -15,6 +15,7 @@
 package timecode
 
 import (
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -61,6 +62,11 @@ var (
 		Rate96,
 		Rate100,
 		Rate120,
+		Rate47952,
+		Rate5994,
+		Rate11988,
+		Rate120DF,
+		Rate2997,
 	}
 )
 
@@ -119,3 +125,256 @@ func TestTimecodeDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestUserRateRegistry(t *testing.T) {
+	a := NewRate(25, 2)
+	b := NewRate(50, 4)
+	if !a.IsUserDefined() {
+		t.Errorf("Rate %s should be user-defined", a.RationalString())
+	}
+	if a != b {
+		t.Errorf("Rates %s and %s should be equal", a.RationalString(), b.RationalString())
+	}
+	ia, _ := rateId(a)
+	if ib, err := rateId(b); ia != ib || err != nil {
+		t.Errorf("Rates %s and %s should share a registry id, got %d and %d (%v)", a.RationalString(), b.RationalString(), ia, ib, err)
+	}
+	if c := rateFromId(ia); c != a {
+		t.Errorf("Registry lookup mismatch %s != %s", c.RationalString(), a.RationalString())
+	}
+	if id, _ := rateId(Rate25); id != R_25 {
+		t.Errorf("Standard rate should keep enum id, got %d", id)
+	}
+}
+
+func TestUserRateRegistryFull(t *testing.T) {
+	userRatesMu.Lock()
+	saved := userRates
+	userRates = make(map[int]Rate)
+	userRatesMu.Unlock()
+	defer func() {
+		userRatesMu.Lock()
+		userRates = saved
+		userRatesMu.Unlock()
+	}()
+
+	// fill all free ids
+	n := 0
+	for ; n < 32; n++ {
+		if err := RegisterRate(NewRate(1000+n, 7)); err != nil {
+			break
+		}
+	}
+	if n == 0 || n == 32 {
+		t.Fatalf("Wrong number of free rate ids: %d", n)
+	}
+	r := NewRate(2000, 7)
+	if err := RegisterRate(r); err == nil {
+		t.Errorf("Expected error when registry is full")
+	}
+	// registered and standard rates keep working
+	if err := RegisterRate(NewRate(1000, 7)); err != nil {
+		t.Errorf("Registered rate failed: %v", err)
+	}
+	if tc := New(0, Rate25); tc.Rate() != Rate25 {
+		t.Errorf("Wrong rate: %s", tc.Rate().RationalString())
+	}
+	// 29.97 non-drop-frame has a standard id
+	if tc, err := Parse("00:00:01:00@29.97"); err != nil || tc.Rate() != Rate2997 {
+		t.Errorf("Wrong 29.97 non-drop-frame rate: %s (%v)", tc.Rate().RationalString(), err)
+	}
+	// parsers fail instead of panicking
+	for _, s := range []string{"00:00:01:00@2000/7", "00:00:01:00@22.5"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("[Case %s] Expected Parse error when registry is full", s)
+		}
+		var x Timecode
+		if err := x.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("[Case %s] Expected UnmarshalText error when registry is full", s)
+		}
+		if err := x.Scan(s); err == nil {
+			t.Errorf("[Case %s] Expected Scan error when registry is full", s)
+		}
+	}
+	if _, err := ParseFrameCode("00:00:01:00", r); err == nil {
+		t.Errorf("Expected ParseFrameCode error when registry is full")
+	}
+	if _, err := ParseRationalTime("1s", r); err == nil {
+		t.Errorf("Expected ParseRationalTime error when registry is full")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected New to panic when registry is full")
+		}
+	}()
+	New(0, r)
+}
+
+func TestRateFrameRoundtrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i, v := range RateDurationTestcases {
+		// largest frame number whose duration fits into a timecode
+		max := v.Frames(time.Duration(time_mask >> 1))
+		frames := []int64{0, 1, 2, max - 1, max}
+		for j := 0; j < 10000; j++ {
+			frames = append(frames, rng.Int63n(max))
+		}
+		for _, f := range frames {
+			for _, x := range []int64{f, -f} {
+				tc := New(0, v)
+				if y := tc.SetFrame(x).Frame(); y != x {
+					t.Errorf("[Case #%.2d] Wrong frame at rate %s: expected=%d got=%d", i, v.RationalString(), x, y)
+				}
+			}
+			d := v.Duration(f)
+			if d > v.Duration(f+1) || v.Frames(d) != f || v.Frames(v.Duration(f+1)-1) != f {
+				t.Errorf("[Case #%.2d] Frame %d at rate %s not inverse to duration %d", i, f, v.RationalString(), d)
+			}
+		}
+	}
+}
+
+func TestRateFrameBoundaries(t *testing.T) {
+	for i, v := range RateDurationTestcases {
+		max := v.Frames(time.Duration(time_mask >> 1))
+		check := func(f int64) {
+			for _, x := range []int64{f - 1, f, f + 1} {
+				if x < 0 || x > max {
+					continue
+				}
+				for _, y := range []int64{x, -x} {
+					tc := New(0, v)
+					if z := tc.SetFrame(y).Frame(); z != y {
+						t.Fatalf("[Case #%.2d] Wrong frame at rate %s: expected=%d got=%d", i, v.RationalString(), y, z)
+					}
+				}
+			}
+		}
+
+		// largest durations in both directions, identity rates store
+		// frames in the nanosecond field
+		for _, d := range []time.Duration{time.Duration(time_mask >> 1), -time.Duration(time_mask >> 1)} {
+			if v.enum == IdentityRate.enum || v.enum == IdentityRateDF.enum {
+				break
+			}
+			tc := New(d, v)
+			if f := tc.Frame(); f != v.Frames(d) || tc.Duration() != v.Duration(f) {
+				t.Errorf("[Case #%.2d] Wrong frame for duration %d at rate %s: %d", i, d, v.RationalString(), f)
+			}
+		}
+		check(max)
+		if !v.IsDrop() {
+			continue
+		}
+
+		// every 10-minute boundary across the full range
+		fpm := int64(v.fps) * 60
+		df := int64(v.dropFrames)
+		block := int64(v.framesPer10Min)
+		for f := int64(0); f <= max; f += block {
+			check(f)
+			if l := frameToLabel(f, v); l%(10*fpm) != 0 {
+				t.Fatalf("[Case #%.2d] Wrong label for 10-minute boundary %d at rate %s: %d", i, f, v.RationalString(), l)
+			}
+		}
+
+		// every 1-minute boundary during the first and the last day
+		day := v.FramesPerDay()
+		for _, start := range []int64{0, (max - day) / block * block} {
+			for b := start; b < start+day; b += block {
+				for m := int64(1); m < 10; m++ {
+					f := b + fpm + (m-1)*(fpm-df)
+					check(f)
+					if l := frameToLabel(f, v); l%fpm != df || frameToLabel(f-1, v)%fpm != fpm-1 {
+						t.Fatalf("[Case #%.2d] Wrong label for minute boundary %d at rate %s: %d", i, f, v.RationalString(), l)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRateLongForm(t *testing.T) {
+	// 23.976 and 29.97 run over 24h of material without drift
+	for _, v := range []Rate{Rate23976, Rate30DF, Rate60DF} {
+		n := v.FramesPerDay()
+		num, den := v.Fraction()
+		if d, x := v.Duration(n), time.Duration(n*int64(den)*int64(time.Second)/int64(num)); d != x {
+			t.Errorf("Wrong duration at rate %s: expected=%d got=%d", v.RationalString(), x, d)
+		}
+		tc := New(v.Duration(n), v)
+		if f := tc.Frame(); f != n {
+			t.Errorf("Wrong frame at rate %s: expected=%d got=%d", v.RationalString(), n, f)
+		}
+	}
+}
+
+type RateParseTestcase struct {
+	Value string
+	Rate  Rate
+}
+
+var (
+	RateParseTestcases []RateParseTestcase = []RateParseTestcase{
+		RateParseTestcase{"23.976", Rate23976},
+		RateParseTestcase{"24000/1001", Rate23976},
+		RateParseTestcase{"29.97", Rate30DF},
+		RateParseTestcase{"29.97DF", Rate30DF},
+		RateParseTestcase{"47.952", Rate47952},
+		RateParseTestcase{"48000/1001", Rate47952},
+		RateParseTestcase{"11", NewRate(11, 1)},
+		RateParseTestcase{"59.94", Rate60DF},
+		RateParseTestcase{"59.94DF", Rate60DF},
+		RateParseTestcase{"59.94NDF", Rate5994},
+		RateParseTestcase{"59.94ndf", Rate5994},
+		RateParseTestcase{"12", NewRate(12, 1)},
+		RateParseTestcase{"119.88", Rate120DF},
+		RateParseTestcase{"120000/1001", Rate120DF},
+		RateParseTestcase{"119.88NDF", Rate11988},
+		RateParseTestcase{"13", NewRate(13, 1)},
+		RateParseTestcase{"26", NewRate(26, 1)},
+		RateParseTestcase{"120", Rate120},
+		RateParseTestcase{"16", NewRate(16, 1)},
+		RateParseTestcase{"25NDF", Rate25},
+		// indexes of the original standard rates
+		RateParseTestcase{"3", Rate25},
+		RateParseTestcase{"10", Rate120},
+		RateParseTestcase{"20", Rate30DF},
+		RateParseTestcase{"23", Rate60DF},
+	}
+)
+
+func TestParseRate(t *testing.T) {
+	for _, v := range RateParseTestcases {
+		r, err := ParseRate(v.Value)
+		if err != nil {
+			t.Errorf("[Case %s] unexpected error: %v", v.Value, err)
+		}
+		if r != v.Rate {
+			t.Errorf("[Case %s] Wrong rate: expected=%s/%s got=%s/%s", v.Value, v.Rate.RationalString(), v.Rate.IndexString(), r.RationalString(), r.IndexString())
+		}
+	}
+	for _, v := range []string{"25DF", "DF", "x/1001"} {
+		if _, err := ParseRate(v); err == nil {
+			t.Errorf("[Case %s] expected error", v)
+		}
+	}
+}
+
+func TestRateDropVariants(t *testing.T) {
+	for _, v := range []Rate{Rate30DF, Rate60DF, Rate120DF} {
+		n := v.NonDrop()
+		if n.IsDrop() || !n.IsEqual(v) {
+			t.Errorf("Wrong non-drop-frame variant for %s: %s", v.FloatString(), n.IndexString())
+		}
+		if d, ok := n.Drop(); !ok || d != v {
+			t.Errorf("Wrong drop-frame variant for %s: %s", n.FloatString(), d.IndexString())
+		}
+	}
+	if r := Rate30DF.NonDrop(); r != Rate2997 || r.fps != 30 || r.framesPer10Min != 18000 {
+		t.Errorf("Wrong 29.97 non-drop-frame rate: %d fps, %d frames per 10min", r.fps, r.framesPer10Min)
+	}
+	if _, ok := Rate25.Drop(); ok {
+		t.Errorf("Rate 25 should not have a drop-frame variant")
+	}
+}
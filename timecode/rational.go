@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Rational time strings
+//
+// Final Cut Pro XML expresses times and durations as rational number of
+// seconds like '1001/30000s' or whole seconds like '3600s'. The format
+// element's frameDuration attribute uses the same syntax and its tcFormat
+// attribute selects between drop-frame ('DF') and non-drop-frame ('NDF')
+// timecode labels.
+
+package timecode
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ParseRationalTime converts the rational time string s of form 'N/Ds' or
+// 'Ns' to a timecode at rate r. Times between frame boundaries resolve to
+// the frame that contains them, which for negative times is the frame
+// further away from zero.
+func ParseRationalTime(s string, r Rate) (Timecode, error) {
+	if !r.IsValid() {
+		return Invalid, fmt.Errorf("timecode: parsing rational time \"%s\": invalid rate", s)
+	}
+	num, den, err := parseRational(s)
+	if err != nil {
+		return Invalid, err
+	}
+	hi, d := bits.Mul64(den, uint64(r.rateDen))
+	if hi != 0 {
+		return Invalid, fmt.Errorf("timecode: parsing rational time \"%s\": value out of range", s)
+	}
+	f := mulDivFloor(num, uint64(r.rateNum), d)
+	if max := r.maxFrames(); f > max || f < -max {
+		return Invalid, fmt.Errorf("timecode: parsing rational time \"%s\": value out of range", s)
+	}
+	return newTimecode(r.Duration(f), r)
+}
+
+// RationalTimeString returns the start time of the timecode's frame as
+// rational time string. Whole seconds are written as 'Ns', all other times
+// as 'N/Ds' with the rate's numerator as denominator, e.g. '1001/30000s'.
+func (t Timecode) RationalTimeString() string {
+	r := t.Rate()
+	return formatRational(t.Frame()*int64(r.rateDen), int64(r.rateNum))
+}
+
+// ParseFrameDuration returns the rate for a frame duration string like
+// '1001/30000s' and the timecode format tcFormat. 'DF' selects the
+// drop-frame variant of the rate while 'NDF' and an empty format select
+// non-drop-frame labels.
+func ParseFrameDuration(s, tcFormat string) (Rate, error) {
+	num, den, err := parseRational(s)
+	if err != nil {
+		return InvalidRate, err
+	}
+	if num <= 0 || den > 1<<31-1 || num > 1<<31-1 {
+		return InvalidRate, fmt.Errorf("timecode: parsing frame duration \"%s\": invalid value", s)
+	}
+	r := NewRate(int(den), int(num))
+	switch strings.ToUpper(tcFormat) {
+	case "DF":
+		if d, ok := r.Drop(); ok {
+			return d, nil
+		}
+		return InvalidRate, fmt.Errorf("timecode: no drop-frame variant of rate %s", r.RationalString())
+	case "NDF", "":
+		return r.NonDrop(), nil
+	default:
+		return InvalidRate, fmt.Errorf("timecode: invalid tcFormat \"%s\"", tcFormat)
+	}
+}
+
+// FrameDurationString returns the frame duration of rate r as rational time
+// string, e.g. '1001/30000s' or '1/25s'.
+func (r Rate) FrameDurationString() string {
+	return formatRational(int64(r.rateDen), int64(r.rateNum))
+}
+
+// TCFormat returns the timecode format of rate r as 'DF' or 'NDF'.
+func (r Rate) TCFormat() string {
+	if r.IsDrop() {
+		return "DF"
+	}
+	return "NDF"
+}
+
+// parseRational reads a rational time string of form 'N/Ds' or 'Ns'.
+func parseRational(s string) (int64, uint64, error) {
+	err := fmt.Errorf("timecode: parsing rational time \"%s\": invalid syntax", s)
+	if !strings.HasSuffix(s, "s") {
+		return 0, 0, err
+	}
+	v := s[:len(s)-1]
+	den := uint64(1)
+	if i := strings.IndexByte(v, '/'); i >= 0 {
+		d, e := strconv.ParseUint(v[i+1:], 10, 63)
+		if e != nil || d == 0 {
+			return 0, 0, err
+		}
+		den, v = d, v[:i]
+	}
+	num, e := strconv.ParseInt(v, 10, 64)
+	if e != nil || num == -1<<63 {
+		return 0, 0, err
+	}
+	return num, den, nil
+}
+
+func formatRational(num, den int64) string {
+	if den == 0 {
+		return "0s"
+	}
+	if num%den == 0 {
+		return strconv.FormatInt(num/den, 10) + "s"
+	}
+	return strconv.FormatInt(num, 10) + "/" + strconv.FormatInt(den, 10) + "s"
+}
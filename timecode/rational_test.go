@@ -0,0 +1,190 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"testing"
+)
+
+type RationalTestcase struct {
+	Id       string
+	Rate     Rate
+	Rational string
+	Frame    int64
+	Timecode string
+}
+
+var RationalTestcases []RationalTestcase = []RationalTestcase{
+	{"23_976", Rate23976, "86486400/24000s", 86400, "01:00:00:00"},
+	{"24", Rate24, "3600s", 86400, "01:00:00:00"},
+	{"25", Rate25, "90001/25s", 90001, "01:00:00:01"},
+	{"29_97DF", Rate30DF, "107999892/30000s", 107892, "01:00:00;00"},
+	{"29_97DF_1", Rate30DF, "1001/30000s", 1, "00:00:00;01"},
+	{"29_97NDF", Rate30DF.NonDrop(), "108108000/30000s", 108000, "01:00:00:00"},
+	{"59_94DF", Rate60DF, "0s", 0, "00:00:00;00"},
+	{"60", Rate60, "1/60s", 1, "00:00:00:01"},
+	{"neg_25", Rate25, "-1/25s", -1, "-00:00:00:01"},
+}
+
+func TestRationalTime(t *testing.T) {
+	for _, v := range RationalTestcases {
+		tc, err := ParseRationalTime(v.Rational, v.Rate)
+		if err != nil {
+			t.Errorf("[Case #%s] ParseRationalTime failed: %v", v.Id, err)
+		}
+		if f := tc.Frame(); f != v.Frame {
+			t.Errorf("[Case #%s] Wrong frame: expected=%d got=%d", v.Id, v.Frame, f)
+		}
+		if s := tc.String(); s != v.Timecode {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s got=%s", v.Id, v.Timecode, s)
+		}
+		if s := tc.RationalTimeString(); s != v.Rational {
+			t.Errorf("[Case #%s] Wrong rational time: expected=%s got=%s", v.Id, v.Rational, s)
+		}
+	}
+}
+
+func TestParseRationalTime(t *testing.T) {
+	for i, v := range []struct {
+		Rational string
+		Rate     Rate
+		Frame    int64
+	}{
+		// times between frames resolve to the containing frame
+		{"1/30s", Rate30DF, 0},
+		{"1000/30000s", Rate30DF, 0},
+		{"2003/60000s", Rate30DF, 1},
+		{"7200/2s", Rate25, 90000},
+		{"3600/1s", Rate25, 90000},
+		// audio times at 48 kHz
+		{"172799828/48000s", Rate30DF, 107892},
+		{"172799827/48000s", Rate30DF, 107891},
+		// negative times round down to the containing frame
+		{"-1/60s", Rate30, -1},
+		{"-1/30s", Rate30, -1},
+		{"-61/60s", Rate30, -31},
+		{"-1000/30000s", Rate30DF, -1},
+		{"-172799827/48000s", Rate30DF, -107892},
+	} {
+		tc, err := ParseRationalTime(v.Rational, v.Rate)
+		if err != nil || tc.Frame() != v.Frame {
+			t.Errorf("[Case #%d] Wrong frame: expected=%d got=%d (%v)", i, v.Frame, tc.Frame(), err)
+		}
+	}
+	for i, s := range []string{"", "s", "3600", "1/0s", "/25s", "1/s", "1.5s", "1/-25s", "a/bs", "1/25"} {
+		if _, err := ParseRationalTime(s, Rate25); err == nil {
+			t.Errorf("[Case #%d] Expected error for %q", i, s)
+		}
+	}
+	if _, err := ParseRationalTime("1s", InvalidRate); err == nil {
+		t.Errorf("Expected error for invalid rate")
+	}
+
+	// huge numerators and denominators are out of range
+	for i, v := range []struct {
+		Rational string
+		Rate     Rate
+	}{
+		{"1/4611686018427387904s", NewRate(101, 4)},
+		{"-1/4611686018427387904s", NewRate(101, 4)},
+		{"1/9223372036854775807s", Rate30DF},
+		{"9223372036854775807s", Rate25},
+		{"-9223372036854775807s", Rate25},
+		{"9223372036854775807/3s", Rate120DF},
+		{"288230377s", Rate25},
+		{"-288230377s", Rate25},
+	} {
+		if tc, err := ParseRationalTime(v.Rational, v.Rate); err == nil {
+			t.Errorf("[Case #%d] Expected range error for %q, got %s", i, v.Rational, tc)
+		}
+	}
+	for i, v := range []struct {
+		Rational string
+		Rate     Rate
+		Frame    int64
+	}{
+		{"1/9007199254740992s", Rate30DF, 0},
+		{"288230376s", Rate25, 7205759400},
+		{"-288230376s", Rate25, -7205759400},
+	} {
+		tc, err := ParseRationalTime(v.Rational, v.Rate)
+		if err != nil || tc.Frame() != v.Frame {
+			t.Errorf("[Case #%d] Wrong frame: expected=%d got=%d (%v)", i, v.Frame, tc.Frame(), err)
+		}
+	}
+}
+
+func TestParseFrameDuration(t *testing.T) {
+	for i, v := range []struct {
+		FrameDuration string
+		TCFormat      string
+		Rate          Rate
+	}{
+		{"1001/24000s", "NDF", Rate23976},
+		{"1001/24000s", "", Rate23976},
+		{"100/2400s", "NDF", Rate24},
+		{"1/25s", "", Rate25},
+		{"100/2500s", "NDF", Rate25},
+		{"1001/30000s", "DF", Rate30DF},
+		{"1001/30000s", "NDF", Rate30DF.NonDrop()},
+		{"1/30s", "NDF", Rate30},
+		{"1001/60000s", "DF", Rate60DF},
+		{"1001/60000s", "NDF", Rate5994},
+		{"1/50s", "NDF", Rate50},
+	} {
+		r, err := ParseFrameDuration(v.FrameDuration, v.TCFormat)
+		if err != nil {
+			t.Errorf("[Case #%d] ParseFrameDuration failed: %v", i, err)
+		}
+		if r != v.Rate {
+			t.Errorf("[Case #%d] Wrong rate: expected=%s got=%s", i, v.Rate.RationalString(), r.RationalString())
+		}
+		if f := r.TCFormat(); f == "DF" != (v.TCFormat == "DF") {
+			t.Errorf("[Case #%d] Wrong tcFormat: %s", i, f)
+		}
+	}
+	for i, v := range []struct {
+		FrameDuration string
+		TCFormat      string
+	}{
+		{"1/25s", "DF"},
+		{"1001/24000s", "DF"},
+		{"1001/30000s", "XDF"},
+		{"0/25s", "NDF"},
+		{"-1/25s", "NDF"},
+		{"1/25", "NDF"},
+	} {
+		if _, err := ParseFrameDuration(v.FrameDuration, v.TCFormat); err == nil {
+			t.Errorf("[Case #%d] Expected error for %s/%s", i, v.FrameDuration, v.TCFormat)
+		}
+	}
+}
+
+func TestFrameDurationString(t *testing.T) {
+	for i, v := range []struct {
+		Rate          Rate
+		FrameDuration string
+	}{
+		{Rate23976, "1001/24000s"},
+		{Rate25, "1/25s"},
+		{Rate30DF, "1001/30000s"},
+		{Rate60DF, "1001/60000s"},
+		{Rate120, "1/120s"},
+	} {
+		if s := v.Rate.FrameDurationString(); s != v.FrameDuration {
+			t.Errorf("[Case #%d] Wrong frame duration: expected=%s got=%s", i, v.FrameDuration, s)
+		}
+	}
+}
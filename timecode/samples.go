@@ -0,0 +1,64 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Audio sample positions
+//
+// Audio files like Broadcast WAV store their position as a count of samples
+// since midnight. Conversions between sample positions and video frames use
+// the exact rational frame duration, so sample n belongs to frame
+// floor(n * rateNum / (sampleRate * rateDen)) and frame f starts at sample
+// ceil(f * sampleRate * rateDen / rateNum).
+
+package timecode
+
+// FromSamples creates a timecode at video rate r for the frame that contains
+// audio sample n at sampleRate. Without a valid rate or sample rate, or when
+// the frame lies beyond the timecode runtime limit, the result is Invalid.
+func FromSamples(n uint64, sampleRate int, r Rate) Timecode {
+	if sampleRate <= 0 || !r.IsValid() {
+		return Invalid
+	}
+	f := int64(mulDiv(n, uint64(r.rateNum), 0, uint64(sampleRate)*uint64(r.rateDen)))
+	if f > r.maxFrames() {
+		return Invalid
+	}
+	return New(r.Duration(f), r)
+}
+
+// Samples returns the position of the first audio sample at sampleRate that
+// belongs to the timecode's frame. Negative timecodes return negative sample
+// positions relative to sample 0 at 00:00:00:00.
+func (t Timecode) Samples(sampleRate int) int64 {
+	if !t.IsValid() || sampleRate <= 0 {
+		return 0
+	}
+	r := t.Rate()
+	return mulDivCeil(t.Frame(), uint64(sampleRate)*uint64(r.rateDen), uint64(r.rateNum))
+}
+
+// mulDivFloor returns floor(a*b/d) for signed a using 128bit intermediates.
+func mulDivFloor(a int64, b, d uint64) int64 {
+	if a < 0 {
+		return -int64(mulDiv(uint64(-a), b, d-1, d))
+	}
+	return int64(mulDiv(uint64(a), b, 0, d))
+}
+
+// mulDivCeil returns ceil(a*b/d) for signed a using 128bit intermediates.
+func mulDivCeil(a int64, b, d uint64) int64 {
+	if a < 0 {
+		return -int64(mulDiv(uint64(-a), b, 0, d))
+	}
+	return int64(mulDiv(uint64(a), b, d-1, d))
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+type SamplesTestcase struct {
+	Id         string
+	Rate       Rate
+	SampleRate int
+	Frame      int64
+	Samples    int64
+}
+
+var SamplesTestcases []SamplesTestcase = []SamplesTestcase{
+	{"23_976_48k", Rate23976, 48000, 86400, 172972800},
+	{"24_48k", Rate24, 48000, 86400, 172800000},
+	{"25_48k", Rate25, 48000, 90000, 172800000},
+	{"25_44k", Rate25, 44100, 1, 1764},
+	{"29_97DF_48k", Rate30DF, 48000, 107892, 172799828},
+	{"29_97DF_44k", Rate30DF, 44100, 1, 1472},
+	{"29_97DF_day", Rate30DF, 48000, 2589408, 4147195853},
+	{"30_96k", Rate30, 96000, 108000, 345600000},
+	{"59_94DF_48k", Rate60DF, 48000, 215784, 172799828},
+	{"59_94NDF_96k", Rate5994, 96000, 3, 4805},
+	{"neg_29_97DF", Rate30DF, 48000, -1, -1601},
+}
+
+func TestSamples(t *testing.T) {
+	for _, v := range SamplesTestcases {
+		tc := NewFrameCode(v.Frame, v.Rate).Timecode()
+		if s := tc.Samples(v.SampleRate); s != v.Samples {
+			t.Errorf("[Case #%s] Wrong sample position: expected=%d got=%d", v.Id, v.Samples, s)
+		}
+		if v.Samples < 0 {
+			continue
+		}
+		if x := FromSamples(uint64(v.Samples), v.SampleRate, v.Rate); x != tc {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s got=%s", v.Id, tc.StringWithRate(), x.StringWithRate())
+		}
+		// the sample before the first sample of a frame belongs to the
+		// previous frame
+		if v.Samples > 0 {
+			if f := FromSamples(uint64(v.Samples-1), v.SampleRate, v.Rate).Frame(); f != v.Frame-1 {
+				t.Errorf("[Case #%s] Wrong frame: expected=%d got=%d", v.Id, v.Frame-1, f)
+			}
+		}
+	}
+}
+
+func TestSamplesRoundtrip(t *testing.T) {
+	for _, r := range []Rate{Rate23976, Rate25, Rate30DF, Rate5994, Rate60DF} {
+		for _, sr := range []int{44100, 48000, 96000} {
+			for f := int64(0); f < 3000; f += 7 {
+				tc := NewFrameCode(f, r).Timecode()
+				if x := FromSamples(uint64(tc.Samples(sr)), sr, r); x != tc {
+					t.Errorf("[Case #%s/%d] Wrong timecode: expected=%s got=%s", r.FloatString(), sr, tc, x)
+				}
+			}
+		}
+	}
+}
+
+func TestFromSamplesInvalid(t *testing.T) {
+	if x := FromSamples(48000, 0, Rate25); x != Invalid {
+		t.Errorf("Expected invalid timecode, got %s", x)
+	}
+	if x := FromSamples(48000, 48000, InvalidRate); x != Invalid {
+		t.Errorf("Expected invalid timecode, got %s", x)
+	}
+
+	// sample positions beyond the timecode runtime limit
+	for i, n := range []uint64{1 << 63, math.MaxUint64, 13835058055680} {
+		if x := FromSamples(n, 48000, Rate25); x != Invalid {
+			t.Errorf("[Case #%d] Expected invalid timecode, got %s", i, x)
+		}
+	}
+	for i, n := range []int64{math.MaxInt64, -math.MaxInt64} {
+		if x := SubframeFromSamples(n, 48000, Rate25, Subframes80); x.Timecode != Invalid {
+			t.Errorf("[Case #%d] Expected invalid sub-frame, got %s", i, x.Timecode)
+		}
+	}
+	last := New(Rate25.Duration(Rate25.maxFrames()), Rate25)
+	if n := last.Samples(48000); FromSamples(uint64(n), 48000, Rate25) != last {
+		t.Errorf("Wrong timecode for last sample position %d", n)
+	}
+	if s := New(time.Second, Rate25).Samples(0); s != 0 {
+		t.Errorf("Expected zero samples, got %d", s)
+	}
+	// timecodes without rate use nanosecond frames
+	if s := New(time.Second+time.Nanosecond, IdentityRate).Samples(48000); s != 48001 {
+		t.Errorf("Wrong sample position: expected=48001 got=%d", s)
+	}
+}
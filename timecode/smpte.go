@@ -0,0 +1,281 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// SMPTE ST 12-1-2014 packed timecode
+// SMPTE ST 12-3-2016 high frame rate timecode
+//
+// The packed timecode word uses the same layout as the first 4 bytes of
+// the SMPTE ST 331 timecode element. LTC bit positions are given in
+// parentheses.
+//
+//   bit  0-3   frame units (0-3)
+//   bit  4-5   frame tens (8-9)
+//   bit  6     drop frame flag (10)
+//   bit  7     color frame flag (11)
+//   bit  8-11  seconds units (16-19)
+//   bit 12-14  seconds tens (24-26)
+//   bit 15     flag (27)
+//   bit 16-19  minutes units (32-35)
+//   bit 20-22  minutes tens (40-42)
+//   bit 23     flag (43)
+//   bit 24-27  hours units (48-51)
+//   bit 28-29  hours tens (56-57)
+//   bit 30     flag (58)
+//   bit 31     flag (59)
+//
+// The meaning of the flags at bit 15, 23 and 31 depends on whether the
+// timecode counts 25 fps (and multiples) or 30 fps frames.
+
+package timecode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SMPTEFlags holds the flag bits of a packed SMPTE timecode independent of
+// their position, which differs between 25 fps and 30 fps timecodes.
+type SMPTEFlags struct {
+	// DropFrame indicates a drop-frame timecode. When packing, the flag is
+	// always taken from the timecode's rate.
+	DropFrame bool
+	// ColorFrame indicates that the timecode is locked to the color frame
+	// sequence of a composite video signal.
+	ColorFrame bool
+	// FieldMark is the field mark flag in VITC. LTC uses the same bit as
+	// biphase mark polarity correction bit.
+	FieldMark bool
+	// BGF holds the binary group flags BGF0, BGF1 and BGF2.
+	BGF UserBitsFormat
+}
+
+// FlagsFromSMPTE returns the flags of packed SMPTE timecode tc at rate r.
+// At high frame rates the field mark and, above 60 fps, the color frame
+// flag identify the frame within a frame pair and are not reported.
+func FlagsFromSMPTE(tc uint32, r Rate) SMPTEFlags {
+	m := r.framesPerPair()
+	f := SMPTEFlags{
+		DropFrame:  tc&smpteDropFrame > 0,
+		ColorFrame: m <= 2 && tc&smpteColorFrame > 0,
+		FieldMark:  m == 1 && tc&r.fieldMark() > 0,
+	}
+	for i, b := range r.bgfBits() {
+		if tc&b > 0 {
+			f.BGF |= 1 << uint(i)
+		}
+	}
+	return f
+}
+
+// Pack returns the flag bits for a packed SMPTE timecode at rate r. Flags
+// used for frame pair identification at rate r are ignored.
+func (f SMPTEFlags) Pack(r Rate) uint32 {
+	var tc uint32
+	m := r.framesPerPair()
+	if f.DropFrame {
+		tc |= smpteDropFrame
+	}
+	if f.ColorFrame && m <= 2 {
+		tc |= smpteColorFrame
+	}
+	if f.FieldMark && m == 1 {
+		tc |= r.fieldMark()
+	}
+	for i, b := range r.bgfBits() {
+		if f.BGF&(1<<uint(i)) > 0 {
+			tc |= b
+		}
+	}
+	return tc
+}
+
+// SMPTEWithFlags works like SMPTE but also returns user bits u and sets the
+// flags from f. The drop-frame flag always follows the timecode's rate.
+func (t Timecode) SMPTEWithFlags(u UserBits, f SMPTEFlags) (uint32, uint32) {
+	t = t.Abs()
+	r := t.Rate()
+	if r.FramesPerDay() > 0 {
+		// ST 12-1 has no flag to signal midnight rollovers
+		t, _ = t.Wrap()
+	}
+	f.DropFrame = false
+	return packLabel(t.adjustedFrame(r), r) | f.Pack(r), uint32(u)
+}
+
+// FromSMPTEWithFlags unpacks the SMPTE timecode from tc at rate r like
+// FromSMPTEAtRate and also returns the user bits from bits and the flags
+// from tc. It reverses SMPTEWithFlags.
+func FromSMPTEWithFlags(tc, bits uint32, r Rate) (Timecode, UserBits, SMPTEFlags) {
+	f := FlagsFromSMPTE(tc, r)
+	if tc&smpteDropFrame > 0 {
+		r, _ = r.Drop()
+	} else {
+		r = r.NonDrop()
+	}
+	l := unpackLabel(tc, r)
+	return New(r.Duration(labelToFrame(l, r)), r), UserBits(bits), f
+}
+
+// flag bits in a packed SMPTE timecode
+const (
+	smpteDropFrame  uint32 = 1 << 6
+	smpteColorFrame uint32 = 1 << 7
+	smpteFlag27     uint32 = 1 << 15
+	smpteFlag43     uint32 = 1 << 23
+	smpteFlag58     uint32 = 1 << 30
+	smpteFlag59     uint32 = 1 << 31
+)
+
+// framesPerPair returns the number of frames that share a single ST 12-1
+// frame address at rate r. High frame rates above 30 fps count pairs of 2
+// (up to 60 fps) or groups of 4 frames (up to 120 fps) per ST 12-3. Rates
+// above 120 fps are not covered by ST 12-3 and cannot be packed.
+func (r Rate) framesPerPair() int {
+	switch {
+	case r.fps > 120:
+		return 1
+	case r.fps > 60:
+		return 4
+	case r.fps > 30:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// is25 indicates if rate r uses the 25 fps flag bit layout.
+func (r Rate) is25() bool {
+	return r.fps == 25*r.framesPerPair()
+}
+
+// fieldMark returns the flag bit used as field mark, polarity correction
+// and frame pair identifier at rate r.
+func (r Rate) fieldMark() uint32 {
+	if r.is25() {
+		return smpteFlag59
+	}
+	return smpteFlag27
+}
+
+// bgfBits returns the flag bits used for binary group flags BGF0, BGF1 and
+// BGF2 at rate r.
+func (r Rate) bgfBits() [3]uint32 {
+	if r.is25() {
+		return [3]uint32{smpteFlag27, smpteFlag58, smpteFlag43}
+	}
+	return [3]uint32{smpteFlag43, smpteFlag58, smpteFlag59}
+}
+
+// packLabel returns label counter l at rate r as packed SMPTE timecode.
+//
+// For high frame rates the frame field counts frame pairs and the field
+// mark flag identifies the first (0) or second (1) frame of a pair. At
+// rates above 60 fps the second identification bit is carried in the
+// color frame flag, which has no meaning for progressive high frame rate
+// signals.
+func packLabel(l int64, r Rate) uint32 {
+	fps := int64(r.fps)
+	m := int64(r.framesPerPair())
+	ff := l % fps
+	ss := l / fps % 60
+	mm := l / (fps * 60) % 60
+	hh := l / (fps * 3600)
+	n := ff % m
+	ff /= m
+	tc := uint32((hh/10)<<28 + hh%10<<24 + mm/10<<20 + mm%10<<16 + ss/10<<12 + ss%10<<8 + ff/10<<4 + ff%10)
+	if r.IsDrop() {
+		tc |= smpteDropFrame
+	}
+	if n&1 > 0 {
+		tc |= r.fieldMark()
+	}
+	if n&2 > 0 {
+		tc |= smpteColorFrame
+	}
+	return tc
+}
+
+// unpackLabel reverses packLabel and returns the label counter of the packed
+// SMPTE timecode tc at rate r.
+func unpackLabel(tc uint32, r Rate) int64 {
+	fps := int64(r.fps)
+	m := int64(r.framesPerPair())
+	hh := int64((tc>>28&0x03)*10 + (tc >> 24 & 0x0F))
+	mm := int64((tc>>20&0x07)*10 + (tc >> 16 & 0x0F))
+	ss := int64((tc>>12&0x07)*10 + (tc >> 8 & 0x0F))
+	ff := int64((tc>>4&0x03)*10 + (tc & 0x0F))
+	l := ((hh*60+mm)*60+ss)*fps + ff*m
+	if m > 1 && tc&r.fieldMark() > 0 {
+		l += 1
+	}
+	if m > 2 && tc&smpteColorFrame > 0 {
+		l += 2
+	}
+	return l
+}
+
+// formatPairLabel returns label counter l at rate r as string 'hh:mm:ss:ff.n'
+// where ff counts frame pairs. Rates without frame pairs use formatLabel.
+func formatPairLabel(l int64, r Rate) string {
+	m := int64(r.framesPerPair())
+	if m == 1 {
+		return formatLabel(l, r)
+	}
+	fps := int64(r.fps)
+	ff := l % fps
+	s := formatLabel((l-ff)/m+ff/m, Rate{fps: r.fps / int(m), enum: r.enum})
+	return s + "." + strconv.FormatInt(ff%m, 10)
+}
+
+// parsePairLabel reads an address label of form 'hh:mm:ss:ff.n' and returns
+// the label counter at rate r.
+func parsePairLabel(s string, r Rate) (int64, error) {
+	idx := strings.LastIndex(s, ".")
+	m := int64(r.framesPerPair())
+	n, err := strconv.ParseUint(s[idx+1:], 10, 64)
+	if err != nil || m == 1 || int64(n) >= m {
+		return 0, fmt.Errorf("timecode: parsing timecode \"%s\": invalid frame pair", s)
+	}
+	l, err := parseLabel(s[:idx], Rate{fps: r.fps / int(m)})
+	if err != nil {
+		return 0, err
+	}
+	fps := int64(r.fps) / m
+	ff := l % fps
+	return (l-ff)*m + ff*m + int64(n), nil
+}
+
+// InterleaveSMPTE interleaves packed SMPTE timecode tc and user bits into the
+// 64 data bits of a LTC or VITC codeword. Bit 0 of the result is codeword
+// bit 0, i.e. nibbles of tc and bits alternate starting with the frame units.
+func InterleaveSMPTE(tc, bits uint32) uint64 {
+	var w uint64
+	for i := uint(0); i < 8; i++ {
+		w |= uint64(tc>>(4*i)&0x0F) << (8 * i)
+		w |= uint64(bits>>(4*i)&0x0F) << (8*i + 4)
+	}
+	return w
+}
+
+// DeinterleaveSMPTE reverses InterleaveSMPTE and returns the packed SMPTE
+// timecode and user bits from the 64 data bits of a LTC or VITC codeword.
+func DeinterleaveSMPTE(w uint64) (uint32, uint32) {
+	var tc, bits uint32
+	for i := uint(0); i < 8; i++ {
+		tc |= uint32(w>>(8*i)&0x0F) << (4 * i)
+		bits |= uint32(w>>(8*i+4)&0x0F) << (4 * i)
+	}
+	return tc, bits
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"testing"
+)
+
+type SMPTETestcase struct {
+	Id         string
+	Rate       Rate
+	Frame      int64
+	SMPTE      uint32
+	PairString string
+}
+
+var (
+	SMPTETestcases []SMPTETestcase = []SMPTETestcase{
+		SMPTETestcase{"25_1", Rate25, 25*3600 + 24, 0x01000024, "01:00:00:24"},
+		SMPTETestcase{"29_1", Rate30DF, 1800, 0x00010042, "00:01:00;02"},
+		SMPTETestcase{"48_1", Rate48, 47, 0x00008023, "00:00:00:23.1"},
+		SMPTETestcase{"50_1", Rate50, 99, 0x80000124, "00:00:01:24.1"},
+		SMPTETestcase{"50_2", Rate50, 98, 0x00000124, "00:00:01:24.0"},
+		SMPTETestcase{"59_1", Rate60DF, 3600, 0x00010042, "00:01:00;02.0"},
+		SMPTETestcase{"59_2", Rate5994, 3600, 0x00010000, "00:01:00:00.0"},
+		SMPTETestcase{"60_1", Rate60, 239, 0x00008329, "00:00:03:29.1"},
+		SMPTETestcase{"96_1", Rate96, 95, 0x000080A3, "00:00:00:23.3"},
+		SMPTETestcase{"100_1", Rate100, 98, 0x000000A4, "00:00:00:24.2"},
+		SMPTETestcase{"100_2", Rate100, 99, 0x800000A4, "00:00:00:24.3"},
+		SMPTETestcase{"119_1", Rate120DF, 7200, 0x00010042, "00:01:00;02.0"},
+		SMPTETestcase{"119_2", Rate120DF, 7201, 0x00018042, "00:01:00;02.1"},
+		SMPTETestcase{"120_1", Rate120, 119, 0x000080A9, "00:00:00:29.3"},
+	}
+)
+
+func TestSMPTEHighFrameRate(t *testing.T) {
+	for _, v := range SMPTETestcases {
+		tc := New(v.Rate.Duration(v.Frame), v.Rate)
+		x, _ := tc.SMPTE()
+		if x != v.SMPTE {
+			t.Errorf("[Case #%s] Wrong SMPTE: expected=%08x got=%08x", v.Id, v.SMPTE, x)
+		}
+		if y := FromSMPTEAtRate(x, 0, v.Rate); y != tc {
+			t.Errorf("[Case #%s] Wrong SMPTE roundtrip: expected=%s got=%s", v.Id, tc, y)
+		}
+		if s := tc.PairString(); s != v.PairString {
+			t.Errorf("[Case #%s] Wrong pair string: expected=%s got=%s", v.Id, v.PairString, s)
+		}
+		p, err := Parse(v.PairString + "@" + v.Rate.FloatString())
+		if err != nil {
+			t.Errorf("[Case #%s] unexpected error: %v", v.Id, err)
+		}
+		if p != tc {
+			t.Errorf("[Case #%s] Wrong pair string parse result: expected=%s got=%s", v.Id, tc, p)
+		}
+	}
+}
+
+func TestSMPTERoundtrip(t *testing.T) {
+	for _, r := range RateDurationTestcases {
+		n := r.FramesPerDay()
+		for _, base := range []int64{0, n / 2, n - 3*int64(r.fps)*60} {
+			for f := base; f < base+3*int64(r.fps)*60; f++ {
+				tc := New(r.Duration(f), r)
+				x, _ := tc.SMPTE()
+				if ff := (x>>4&0x03)*10 + x&0x0F; x&0x0F > 9 || ff >= 30 {
+					t.Fatalf("[Rate %s] Invalid frame field at frame %d: %08x", r.FloatString(), f, x)
+				}
+				if y := FromSMPTEAtRate(x, 0, r); y != tc {
+					t.Fatalf("[Rate %s] Wrong SMPTE roundtrip at frame %d: expected=%s got=%s", r.FloatString(), f, tc, y)
+				}
+			}
+		}
+	}
+}
+
+func TestParsePairInvalid(t *testing.T) {
+	for _, v := range []string{"00:00:01:00.1@25", "00:00:01:00.2@50", "00:00:01:00.x@50", "00:00:01:00.1"} {
+		if _, err := Parse(v); err == nil {
+			t.Errorf("Expected error parsing %q", v)
+		}
+	}
+}
+
+type SMPTEFlagsTestcase struct {
+	Id    string
+	Rate  Rate
+	Flags SMPTEFlags
+	Bits  uint32
+}
+
+var (
+	SMPTEFlagsTestcases []SMPTEFlagsTestcase = []SMPTEFlagsTestcase{
+		SMPTEFlagsTestcase{"25_cf", Rate25, SMPTEFlags{ColorFrame: true}, 0x00000080},
+		SMPTEFlagsTestcase{"25_fm", Rate25, SMPTEFlags{FieldMark: true}, 0x80000000},
+		SMPTEFlagsTestcase{"25_bgf", Rate25, SMPTEFlags{BGF: 7}, 0x40808000},
+		SMPTEFlagsTestcase{"25_all", Rate25, SMPTEFlags{ColorFrame: true, FieldMark: true, BGF: 7}, 0xC0808080},
+		SMPTEFlagsTestcase{"30_cf", Rate30, SMPTEFlags{ColorFrame: true}, 0x00000080},
+		SMPTEFlagsTestcase{"30_fm", Rate30, SMPTEFlags{FieldMark: true}, 0x00008000},
+		SMPTEFlagsTestcase{"30_bgf", Rate30, SMPTEFlags{BGF: 7}, 0xC0800000},
+		SMPTEFlagsTestcase{"29_all", Rate30DF, SMPTEFlags{DropFrame: true, ColorFrame: true, FieldMark: true, BGF: 7}, 0xC08080C0},
+		SMPTEFlagsTestcase{"24_bgf1", Rate24, SMPTEFlags{BGF: 2}, 0x40000000},
+		SMPTEFlagsTestcase{"50_cf", Rate50, SMPTEFlags{ColorFrame: true, BGF: 1}, 0x00008080},
+		SMPTEFlagsTestcase{"60_bgf", Rate60, SMPTEFlags{BGF: 4}, 0x80000000},
+		SMPTEFlagsTestcase{"120_bgf", Rate120, SMPTEFlags{BGF: 3}, 0x40800000},
+	}
+)
+
+func TestSMPTEFlags(t *testing.T) {
+	for _, v := range SMPTEFlagsTestcases {
+		if b := v.Flags.Pack(v.Rate); b != v.Bits {
+			t.Errorf("[Case #%s] Wrong flag bits: expected=%08x got=%08x", v.Id, v.Bits, b)
+		}
+		if f := FlagsFromSMPTE(v.Bits, v.Rate); f != v.Flags {
+			t.Errorf("[Case #%s] Wrong flags: expected=%+v got=%+v", v.Id, v.Flags, f)
+		}
+
+		// flags survive packing with a timecode
+		tc := New(v.Rate.Duration(4321), v.Rate)
+		x, _ := tc.SMPTEWithFlags(0, v.Flags)
+		if f := FlagsFromSMPTE(x, v.Rate); f != v.Flags {
+			t.Errorf("[Case #%s] Wrong flags after packing: expected=%+v got=%+v", v.Id, v.Flags, f)
+		}
+		if y := FromSMPTEAtRate(x, 0, v.Rate); y != tc {
+			t.Errorf("[Case #%s] Flags changed timecode: expected=%s got=%s", v.Id, tc, y)
+		}
+	}
+}
+
+func TestSMPTEFlagsHighFrameRate(t *testing.T) {
+	// frame pair bits are not reported as flags
+	f := SMPTEFlags{ColorFrame: true, FieldMark: true}
+	if b := f.Pack(Rate120); b != 0 {
+		t.Errorf("Wrong flag bits at 120 fps: expected=0 got=%08x", b)
+	}
+	if b := f.Pack(Rate60); b != smpteColorFrame {
+		t.Errorf("Wrong flag bits at 60 fps: expected=%08x got=%08x", smpteColorFrame, b)
+	}
+	x, _ := New(Rate120.Duration(119), Rate120).SMPTE()
+	if f := FlagsFromSMPTE(x, Rate120); f != (SMPTEFlags{}) {
+		t.Errorf("Wrong flags at 120 fps: %+v", f)
+	}
+}
+
+func TestInterleaveSMPTE(t *testing.T) {
+	w := InterleaveSMPTE(0x12345678, 0x9ABCDEF0)
+	if w != 0x91A2B3C4D5E6F708 {
+		t.Errorf("Wrong interleaved bits: expected=%016x got=%016x", uint64(0x91A2B3C4D5E6F708), w)
+	}
+	if tc, bits := DeinterleaveSMPTE(w); tc != 0x12345678 || bits != 0x9ABCDEF0 {
+		t.Errorf("Wrong deinterleaved bits: got=%08x/%08x", tc, bits)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Sub-frame positions
+//
+// Audio workstations address positions within a frame either as fixed
+// fractions of a frame, typically 1/80 or 1/100 subframes, or as a number
+// of audio samples after the first sample of the frame. Subframe positions
+// are written with a fifth address field as 'hh:mm:ss:ff:sf' and sample
+// offsets as 'hh:mm:ss:ff+n'. The 'hh:mm:ss:ff.n' notation belongs to ST 12-3
+// frame pairs, see Timecode.PairString.
+
+package timecode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SubframeUnit is the unit of a sub-frame offset. Positive values divide a
+// frame into equal subframes, sample units are created with SampleUnit.
+type SubframeUnit int
+
+const (
+	Subframes80  SubframeUnit = 80
+	Subframes100 SubframeUnit = 100
+)
+
+// SampleUnit returns the unit for sub-frame offsets counted in audio samples
+// at sampleRate.
+func SampleUnit(sampleRate int) SubframeUnit {
+	return SubframeUnit(-sampleRate)
+}
+
+// IsSamples indicates if offsets count audio samples.
+func (u SubframeUnit) IsSamples() bool {
+	return u < 0
+}
+
+// SampleRate returns the sample rate of sample units or zero.
+func (u SubframeUnit) SampleRate() int {
+	if u < 0 {
+		return int(-u)
+	}
+	return 0
+}
+
+// String returns the unit as '1/80' for subframes or '48000Hz' for samples.
+func (u SubframeUnit) String() string {
+	if u.IsSamples() {
+		return strconv.Itoa(u.SampleRate()) + "Hz"
+	}
+	return "1/" + strconv.Itoa(int(u))
+}
+
+// Subframe is a position within the frame addressed by a timecode.
+type Subframe struct {
+	Timecode Timecode
+	// Offset is the position after the start of the frame in units of Unit.
+	Offset int64
+	Unit   SubframeUnit
+}
+
+// SubframeFromSamples returns the sub-frame position of audio sample n at
+// sampleRate for video rate r in units of u. Positions beyond the timecode
+// runtime limit return an Invalid timecode.
+func SubframeFromSamples(n int64, sampleRate int, r Rate, u SubframeUnit) Subframe {
+	if sampleRate <= 0 || u == 0 || !r.IsValid() {
+		return Subframe{Invalid, 0, u}
+	}
+	if sr := u.SampleRate(); sr > 0 && sr != sampleRate {
+		n = mulDivFloor(n, uint64(sr), uint64(sampleRate))
+		sampleRate = sr
+	}
+	k := uint64(sampleRate) * uint64(r.rateDen)
+	f := mulDivFloor(n, uint64(r.rateNum), k)
+	if max := r.maxFrames(); f > max || f < -max {
+		return Subframe{Invalid, 0, u}
+	}
+	t := NewFrameCode(f, r).Timecode()
+	if u.IsSamples() {
+		return Subframe{t, n - t.Samples(sampleRate), u}
+	}
+	return Subframe{t, mulDivFloor(n, uint64(r.rateNum)*uint64(u), k) - f*int64(u), u}
+}
+
+// Samples returns the position of the first audio sample at sampleRate at
+// or after the sub-frame position.
+func (s Subframe) Samples(sampleRate int) int64 {
+	if !s.Timecode.IsValid() || sampleRate <= 0 || s.Unit == 0 {
+		return 0
+	}
+	if sr := s.Unit.SampleRate(); sr > 0 {
+		n := s.Timecode.Samples(sr) + s.Offset
+		if sr == sampleRate {
+			return n
+		}
+		return mulDivCeil(n, uint64(sampleRate), uint64(sr))
+	}
+	r := s.Timecode.Rate()
+	u := int64(s.Unit)
+	return mulDivCeil(s.Timecode.Frame()*u+s.Offset, uint64(sampleRate)*uint64(r.rateDen), uint64(u)*uint64(r.rateNum))
+}
+
+// Convert returns the sub-frame position in units of u. Conversions to
+// coarser units round down.
+func (s Subframe) Convert(u SubframeUnit) Subframe {
+	switch {
+	case u == s.Unit:
+		return s
+	case u.IsSamples():
+		return SubframeFromSamples(s.Samples(u.SampleRate()), u.SampleRate(), s.Timecode.Rate(), u)
+	case s.Unit.IsSamples():
+		return SubframeFromSamples(s.Samples(s.Unit.SampleRate()), s.Unit.SampleRate(), s.Timecode.Rate(), u)
+	default:
+		return Subframe{s.Timecode, s.Offset * int64(u) / int64(s.Unit), u}
+	}
+}
+
+// String returns the position as 'hh:mm:ss:ff:sf' for subframes or as
+// 'hh:mm:ss:ff+n' for sample offsets.
+func (s Subframe) String() string {
+	if s.Unit.IsSamples() {
+		return s.Timecode.String() + "+" + strconv.FormatInt(s.Offset, 10)
+	}
+	return fmt.Sprintf("%s:%02d", s.Timecode, s.Offset)
+}
+
+// ParseSubframe converts the string s of form 'hh:mm:ss:ff:sf' or
+// 'hh:mm:ss:ff+n' to a sub-frame position at rate r. Subframe offsets
+// require a subframe unit u and sample offsets a sample unit. Timecodes
+// without offset start at the beginning of the frame. A rate suffix after
+// an '@' character takes precedence over r. Frame pair notation is
+// rejected.
+func ParseSubframe(s string, r Rate, u SubframeUnit) (Subframe, error) {
+	if !r.IsValid() || u == 0 {
+		return Subframe{Invalid, 0, u}, fmt.Errorf("timecode: parsing subframe \"%s\": invalid rate or unit", s)
+	}
+	v, rate := s, ""
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		v, rate = s[:i], s[i:]
+	}
+	if strings.IndexByte(v, '.') >= 0 {
+		return Subframe{Invalid, 0, u}, fmt.Errorf("timecode: parsing subframe \"%s\": invalid syntax", s)
+	}
+	i, isSamples := strings.IndexByte(v, '+'), true
+	if i < 0 && strings.Count(v, ":")+strings.Count(v, ";") == 4 {
+		i, isSamples = strings.LastIndexByte(v, ':'), false
+	}
+	offset := ""
+	if i >= 0 {
+		if isSamples != u.IsSamples() {
+			return Subframe{Invalid, 0, u}, fmt.Errorf("timecode: parsing subframe \"%s\": offset does not match unit %s", s, u)
+		}
+		v, offset = v[:i], v[i+1:]
+	}
+	f, err := ParseFrameCode(v+rate, r)
+	if err != nil {
+		return Subframe{Invalid, 0, u}, err
+	}
+	x := Subframe{f.Timecode(), 0, u}
+	if i < 0 {
+		return x, nil
+	}
+	n, err := strconv.ParseUint(offset, 10, 63)
+	if err != nil {
+		return Subframe{Invalid, 0, u}, fmt.Errorf("timecode: parsing subframe \"%s\": invalid syntax", s)
+	}
+	x.Offset = int64(n)
+	limit := int64(u)
+	if sr := u.SampleRate(); sr > 0 {
+		limit = x.Timecode.AddFrames(1).Samples(sr) - x.Timecode.Samples(sr)
+	}
+	if x.Offset >= limit {
+		return Subframe{Invalid, 0, u}, fmt.Errorf("timecode: parsing subframe \"%s\": offset out of range", s)
+	}
+	return x, nil
+}
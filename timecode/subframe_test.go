@@ -0,0 +1,194 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"testing"
+)
+
+type SubframeTestcase struct {
+	Id         string
+	String     string
+	Rate       Rate
+	Unit       SubframeUnit
+	SampleRate int
+	Samples    int64
+}
+
+var SubframeTestcases []SubframeTestcase = []SubframeTestcase{
+	{"25_80_48k", "01:00:00:00:40", Rate25, Subframes80, 48000, 172800960},
+	{"25_100_48k", "01:00:00:00:50", Rate25, Subframes100, 48000, 172800960},
+	{"25_80_44k", "00:00:01:00:79", Rate25, Subframes80, 44100, 45842},
+	{"24_100_96k", "00:00:00:01:01", Rate24, Subframes100, 96000, 4040},
+	{"29_97DF_100_48k", "01:00:00;00:50", Rate30DF, Subframes100, 48000, 172800628},
+	{"29_97DF_80_44k", "00:00:00;01:00", Rate30DF, Subframes80, 44100, 1472},
+	{"25_samples_48k", "01:00:00:00+1234", Rate25, SampleUnit(48000), 48000, 172801234},
+	{"25_samples_44k", "00:00:01:00+1763", Rate25, SampleUnit(44100), 44100, 45863},
+	{"29_97DF_samples_48k", "00:00:00;01+1600", Rate30DF, SampleUnit(48000), 48000, 3202},
+	{"29_97DF_samples_96k", "01:00:00;00+0", Rate30DF, SampleUnit(96000), 96000, 345599655},
+}
+
+func TestSubframe(t *testing.T) {
+	for _, v := range SubframeTestcases {
+		s, err := ParseSubframe(v.String, v.Rate, v.Unit)
+		if err != nil {
+			t.Errorf("[Case #%s] ParseSubframe failed: %v", v.Id, err)
+			continue
+		}
+		if x := s.String(); x != v.String {
+			t.Errorf("[Case #%s] Wrong string: expected=%s got=%s", v.Id, v.String, x)
+		}
+		if n := s.Samples(v.SampleRate); n != v.Samples {
+			t.Errorf("[Case #%s] Wrong sample position: expected=%d got=%d", v.Id, v.Samples, n)
+		}
+		if x := SubframeFromSamples(v.Samples, v.SampleRate, v.Rate, v.Unit); x != s {
+			t.Errorf("[Case #%s] Wrong subframe: expected=%s got=%s", v.Id, s, x)
+		}
+	}
+}
+
+func TestSubframeConvert(t *testing.T) {
+	s, _ := ParseSubframe("01:00:00:00+1234", Rate25, SampleUnit(48000))
+	for i, v := range []struct {
+		Unit   SubframeUnit
+		String string
+	}{
+		{SampleUnit(96000), "01:00:00:00+2468"},
+		{SampleUnit(44100), "01:00:00:00+1134"},
+		{Subframes80, "01:00:00:00:51"},
+		{Subframes100, "01:00:00:00:64"},
+		{SampleUnit(48000), "01:00:00:00+1234"},
+	} {
+		if x := s.Convert(v.Unit); x.String() != v.String {
+			t.Errorf("[Case #%d] Wrong subframe: expected=%s got=%s", i, v.String, x)
+		}
+	}
+	s, _ = ParseSubframe("00:00:10;00:40", Rate30DF, Subframes80)
+	if x := s.Convert(Subframes100); x.String() != "00:00:10;00:50" {
+		t.Errorf("Wrong subframe: expected=00:00:10;00:50 got=%s", x)
+	}
+	// subframe boundaries on samples convert exactly
+	for o := int64(0); o < 80; o++ {
+		s := Subframe{NewFrameCode(90000, Rate25).Timecode(), o, Subframes80}
+		x := s.Convert(SampleUnit(48000)).Convert(Subframes80)
+		if x != s {
+			t.Errorf("[Case #%d] Wrong subframe: expected=%s got=%s", o, s, x)
+		}
+	}
+}
+
+func TestSubframeFromSamples(t *testing.T) {
+	// every sample maps to a position at or before it
+	for _, r := range []Rate{Rate23976, Rate25, Rate30DF, Rate60DF} {
+		for _, sr := range []int{44100, 48000, 96000} {
+			for n := int64(0); n < 10000; n += 37 {
+				for _, u := range []SubframeUnit{Subframes80, Subframes100, SampleUnit(sr)} {
+					s := SubframeFromSamples(n, sr, r, u)
+					if s.Offset < 0 || s.Samples(sr) > n {
+						t.Errorf("[Case #%s/%d/%s] Wrong subframe for sample %d: %s", r.FloatString(), sr, u, n, s)
+					}
+				}
+				if s := SubframeFromSamples(n, sr, r, SampleUnit(sr)); s.Samples(sr) != n {
+					t.Errorf("[Case #%s/%d] Wrong sample position: expected=%d got=%d", r.FloatString(), sr, n, s.Samples(sr))
+				}
+			}
+		}
+	}
+}
+
+func TestParseSubframeInvalid(t *testing.T) {
+	for i, v := range []struct {
+		String string
+		Rate   Rate
+		Unit   SubframeUnit
+	}{
+		{"01:00:00:00:80", Rate25, Subframes80},
+		{"01:00:00:00:100", Rate25, Subframes100},
+		{"01:00:00:00+1920", Rate25, SampleUnit(48000)},
+		{"00:00:00;01+1602", Rate30DF, SampleUnit(48000)},
+		{"01:00:00:00+12", Rate25, Subframes80},
+		{"01:00:00:00:12", Rate25, SampleUnit(48000)},
+		{"01:00:00:00:x", Rate25, Subframes80},
+		{"01:00:00:00:-1", Rate25, Subframes80},
+		{"01:00:00:0x:10", Rate25, Subframes80},
+		{"01:00:00:00:", Rate25, Subframes80},
+		{"01:00:00:00+", Rate25, SampleUnit(48000)},
+		{"01:00:00;00;10", Rate30DF, Subframes80},
+		{"01:00:00:00:10", InvalidRate, Subframes80},
+		{"01:00:00:00:10", Rate25, 0},
+	} {
+		if _, err := ParseSubframe(v.String, v.Rate, v.Unit); err == nil {
+			t.Errorf("[Case #%d] Expected error for %s", i, v.String)
+		}
+	}
+	if s, err := ParseSubframe("01:00:00:00", Rate25, Subframes80); err != nil || s.Offset != 0 || s.String() != "01:00:00:00:00" {
+		t.Errorf("Wrong subframe: %s (%v)", s, err)
+	}
+	// the dot in a rate suffix is not an offset separator
+	for i, v := range []struct {
+		String string
+		Unit   SubframeUnit
+		Result string
+		Offset int64
+	}{
+		{"01:00:00;00@29.97", Subframes100, "01:00:00;00:00", 0},
+		{"01:00:00;00:50@29.97", Subframes100, "01:00:00;00:50", 50},
+		{"01:00:00;00+800@29.97", SampleUnit(48000), "01:00:00;00+800", 800},
+		{"01:00:00:00:40@25", Subframes80, "01:00:00:00:40", 40},
+	} {
+		s, err := ParseSubframe(v.String, Rate30DF, v.Unit)
+		if err != nil || s.Offset != v.Offset || s.String() != v.Result {
+			t.Errorf("[Case #%d] Wrong subframe: expected=%s got=%s (%v)", i, v.Result, s, err)
+		}
+	}
+	if s, _ := ParseSubframe("01:00:00:00:40@25", Rate30DF, Subframes80); s.Timecode.Rate() != Rate25 {
+		t.Errorf("Wrong rate: expected=25.0 got=%s", s.Timecode.Rate().FloatString())
+	}
+}
+
+func TestSubframeAndPairSyntax(t *testing.T) {
+	// ParseSubframe rejects ST 12-3 frame pair notation
+	for i, v := range []struct {
+		String string
+		Rate   Rate
+		Unit   SubframeUnit
+	}{
+		{"01:00:00:00.1@120", Rate120, Subframes80},
+		{"01:00:00:59.1@100", Rate100, Subframes100},
+		{"01:00:00:00.1", Rate120, Subframes80},
+		{"01:00:00:00.40@50", Rate50, Subframes80},
+	} {
+		if _, err := ParseSubframe(v.String, v.Rate, v.Unit); err == nil {
+			t.Errorf("[Case #%d] Expected error for %s", i, v.String)
+		}
+	}
+
+	// Parse rejects sub-frame positions
+	for i, s := range []string{"01:00:00:00:40@50", "01:00:00:00:01@120", "01:00:00;00:50@29.97", "01:00:00:00+1234@25"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("[Case #%d] Expected error for %s", i, s)
+		}
+	}
+
+	// both notations of the same position stay apart
+	s, err := ParseSubframe("01:00:00:00:01@120", Rate120, Subframes80)
+	if err != nil || s.Offset != 1 || s.Timecode.Frame() != 432000 {
+		t.Errorf("Wrong subframe: %s (%v)", s, err)
+	}
+	p, err := Parse("01:00:00:00.1@120")
+	if err != nil || p.Frame() != 432001 || p.PairString() != "01:00:00:00.1" {
+		t.Errorf("Wrong frame pair: %s (%v)", p, err)
+	}
+}
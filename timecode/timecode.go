@@ -24,11 +24,14 @@
 // - support SMPTE 24h bit
 
 // Package timecode provides types and primitives to work with SMPTE ST 12-1
-// timecodes at standard and user-defined edit rates. Currently only the DF
-// flag is supported and timecodes cannot be negative.
+// timecodes at standard and user-defined edit rates. Packed SMPTE timecodes
+// may carry user bits and all flag bits in 25 and 30 fps layouts. Timecodes
+// may be negative to express signed offsets.
 //
 // The package supports functions to convert between timecode, frame number
 // and realtime durations as well as functions for timecode calculations.
+// Calculations may optionally wrap around at 24h like broadcast and LTC
+// devices do.
 // Drop-frame and non-drop-frame timecodes are correctly handled and all
 // standard film, video and Television edit rates are supported. You may
 // also use arbitrary user-defined edit rates down to 1ns precision with
@@ -49,11 +52,12 @@ import (
 )
 
 // Timecode represents a duration at nanosecond precision similar to Golang's
-// time.Duration. Unlike time.Duration Timecode cannot be negative.
+// time.Duration. Like time.Duration a Timecode may be negative, which is
+// useful for offsets like pre-roll or audio sync.
 //
-// The 6 most significant bits are used to store an edit rate identifier required for
-// offset calculations. The timecode's duration value occupies the 59 least significant
-// bits which allows for expressing ~9 years of runtime.
+// The 5 most significant bits are used to store an edit rate identifier required for
+// offset calculations. The timecode's signed duration value occupies the 59 least
+// significant bits which allows for expressing ~9 years of runtime in both directions.
 type Timecode uint64
 
 const (
@@ -67,10 +71,32 @@ const (
 )
 
 // New creates a new timecode from a time.Duration and an edit rate. The duration
-// is truncated to the edit rate's interval length before storage.
+// is truncated to the edit rate's interval length before storage. New panics
+// when r is a user-defined rate that cannot be registered, see RegisterRate.
+// Parse and the other parsing functions return an error instead.
 func New(d time.Duration, r Rate) Timecode {
-	d = r.Truncate(d, 2)
-	return Timecode(uint64(r.enum)<<time_bits | (uint64(d) & time_mask))
+	t, err := newTimecode(d, r)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// newTimecode works like New but returns an error when r cannot be
+// registered.
+func newTimecode(d time.Duration, r Rate) (Timecode, error) {
+	id, err := rateId(r)
+	if err != nil {
+		return Invalid, err
+	}
+	// rounding up to the next frame must not leave the runtime limit
+	max := time.Duration(time_mask >> 1)
+	if x := r.Truncate(d, 2); (x > max) == (d > max) && (x < -max) == (d < -max) {
+		d = x
+	} else {
+		d = r.Duration(r.Frames(d))
+	}
+	return Timecode(uint64(id)<<time_bits | (uint64(d) & time_mask)), nil
 }
 
 // IsValid indicates if a timecode is valid. Invalid timecodes have all bits set to 1.
@@ -84,6 +110,37 @@ func (t Timecode) IsZero() bool {
 	return t.Uint64()&time_mask == 0
 }
 
+// IsNegative indicates if the duration part of the timecode is negative.
+func (t Timecode) IsNegative() bool {
+	return t.IsValid() && t.Duration() < 0
+}
+
+// Abs returns the absolute value of timecode t and keeps its rate.
+func (t Timecode) Abs() Timecode {
+	if !t.IsNegative() {
+		return t
+	}
+	return t.Neg()
+}
+
+// Neg returns timecode t with inverted sign and keeps its rate.
+func (t Timecode) Neg() Timecode {
+	return Timecode(uint64(t)&^time_mask | uint64(-t.Duration())&time_mask)
+}
+
+// Compare returns -1 when t is before t2, +1 when t is after t2 and 0 when
+// both timecodes are equal. Only durations are compared, rates are ignored.
+func (t Timecode) Compare(t2 Timecode) int {
+	switch a, b := t.Duration(), t2.Duration(); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // SetFrame sets the timecode to a new frame number f and keeps the timecode's
 // current rate.
 func (t *Timecode) SetFrame(f int64) Timecode {
@@ -97,14 +154,18 @@ func (t *Timecode) SetFrame(f int64) Timecode {
 // set an initial edit rate after parsing a timecode with Parse() when the
 // string did not contain a valid rate.
 func (t *Timecode) SetRate(r Rate) Timecode {
+	if t.IsNegative() {
+		a := t.Abs()
+		*t = a.SetRate(r).Neg()
+		return *t
+	}
+
 	if t.Rate().enum == 0 || t.Rate().enum == df {
 		s := int64(t.Duration() / time.Second)
 		f := int64(t.Duration() % time.Second)
 		frames := s*int64(r.fps) + f
 		if r.IsDrop() {
-			d := frames / int64(r.framesPer10Min)
-			m := s / 60
-			frames -= (m - d) * int64(r.dropFrames)
+			frames = labelToFrame(frames, r)
 		}
 		*t = New(r.Duration(frames), r)
 		return *t
@@ -116,12 +177,10 @@ func (t *Timecode) SetRate(r Rate) Timecode {
 	return t.SetFrame(f)
 }
 
+// Rate returns the timecode's edit rate. User-defined rates are preserved
+// as long as they were packed in the same process.
 func (t Timecode) Rate() Rate {
-	rate, ok := rates[int(uint64(t)>>time_bits)]
-	if !ok {
-		rate = rates[0]
-	}
-	return rate
+	return rateFromId(int(uint64(t) >> time_bits))
 }
 
 // Parse converts the string s to a timecode with optional rate. Without
@@ -135,13 +194,31 @@ func (t Timecode) Rate() Rate {
 // between seconds and frame number.
 //
 // If s contains a '@' character, Parse treats the following substring as rate
-// expression and uses ParseRate() to read it.
+// expression and uses ParseRate() to read it. A 'DF' or 'NDF' rate suffix
+// selects the drop-frame or non-drop-frame variant of the rate regardless of
+// the separator. Without suffix the last separator selects the variant, so
+// 29.97 with a colon counts non-drop-frame labels. With rate, high frame rate
+// timecodes may use the frame pair notation 'hh:mm:ss:ff.n' as returned
+// by PairString().
+//
+// Negative timecodes start with a minus sign '-'.
 func Parse(s string) (Timecode, error) {
 
 	if s == "" {
 		s = Origin
 	}
 
+	if s[0] == '-' {
+		if len(s) == 1 || s[1] == '-' {
+			return Invalid, fmt.Errorf("timecode: parsing timecode \"%s\": invalid syntax", s)
+		}
+		t, err := Parse(s[1:])
+		if err != nil {
+			return Invalid, err
+		}
+		return t.Neg(), nil
+	}
+
 	isDF := strings.Contains(s, ";")
 	hasRate := strings.Contains(s, "@")
 	r := IdentityRate
@@ -155,75 +232,53 @@ func Parse(s string) (Timecode, error) {
 	// strip and parse rate
 	if hasRate {
 		idx := strings.Index(s, "@")
+		rs := s[idx+1:]
 		var err error
-		r, err = ParseRate(s[idx+1:])
+		r, err = ParseRate(rs)
 		if err != nil {
 			return Invalid, err
 		}
 		s = s[:idx]
 
-		// timecode is a frame counter, don't treat it as literal time!
-		var frames int64
-		for i, v := range strings.Split(s, ":") {
-			t, err := strconv.ParseUint(v, 10, 64)
-			if err != nil {
-				// reject timecodes with invalid numbers
-				return Invalid, fmt.Errorf("timecode: parsing timecode \"%s\": invalid syntax", s)
-			}
-			switch i {
-			case 0:
-				frames += int64(t) * 3600 * int64(r.fps)
-			case 1:
-				frames += int64(t) * 60 * int64(r.fps)
-			case 2:
-				frames += int64(t) * int64(r.fps)
-			case 3:
-				frames += int64(t)
-			default:
-				// reject timecodes longer than 4 segements
-				return Invalid, fmt.Errorf("timecode: parsing timecode \"%s\": invalid syntax", s)
-			}
-		}
-
-		// reverse the adjustment for drop frame timecodes
-		if isDF {
-			d := frames / int64(r.framesPer10Min)
-			m := frames % int64(r.framesPer10Min)
-			df := int64(r.dropFrames)
-			frames = frames - 9*df*d - df*((m-df)/int64(r.framesPer10Min/10))
+		// an explicit 'DF' or 'NDF' rate suffix selects the variant,
+		// otherwise the separator selects between drop-frame and
+		// non-drop-frame variants of the same rate
+		switch {
+		case strings.HasSuffix(strings.ToUpper(rs), "DF"):
+			isDF = r.IsDrop()
+		case isDF:
+			r, _ = r.Drop()
+		default:
+			r = r.NonDrop()
 		}
+	}
 
-		return New(r.Duration(frames), r), nil
+	// timecode is a frame counter, don't treat it as literal time! Without
+	// rate we keep the frame number as nanosec part until a rate is set.
+	var frames int64
+	var err error
+	if idx := strings.Index(s, "."); idx >= 0 && hasRate {
+		frames, err = parsePairLabel(s, r)
+	} else {
+		frames, err = parseLabel(s, r)
+	}
+	if err != nil {
+		return Invalid, err
 	}
 
-	// without rate we keep the frame number as nanosec part until a rate is set
-	var d time.Duration
-	for i, v := range strings.Split(s, ":") {
-		t, err := strconv.ParseUint(v, 10, 64)
-		if err != nil {
-			// reject timecodes with invalid numbers
-			return Invalid, fmt.Errorf("timecode: parsing timecode \"%s\": invalid syntax", s)
-		}
-		switch i {
-		case 0:
-			d += time.Duration(t) * time.Hour
-		case 1:
-			d += time.Duration(t) * time.Minute
-		case 2:
-			d += time.Duration(t) * time.Second
-		case 3:
-			d += time.Duration(t)
-		default:
-			// reject timecodes longer than 4 segements
-			return Invalid, fmt.Errorf("timecode: parsing timecode \"%s\": invalid syntax", s)
-		}
+	// reverse the adjustment for drop frame timecodes
+	if isDF {
+		frames = labelToFrame(frames, r)
 	}
 
-	return New(d, r), nil
+	return newTimecode(r.Duration(frames), r)
 }
 
 // FromSMPTE unpacks the SMPTE timecode from tc and also considers the
-// drop-frame bit. User bits are ignored right now.
+// drop-frame bit. The frame number is kept as raw value until a rate is
+// set, which does not work for high frame rate timecodes. Use
+// FromSMPTEAtRate for those and FromSMPTEWithUserBits to also read the
+// user bits.
 func FromSMPTE(tc uint32, bits uint32) Timecode {
 	h := uint64((tc>>28&0x03)*10 + (tc >> 24 & 0x0F))
 	m := uint64((tc>>20&0x07)*10 + (tc >> 16 & 0x0F))
@@ -231,7 +286,7 @@ func FromSMPTE(tc uint32, bits uint32) Timecode {
 	f := uint64((tc>>4&0x03)*10 + (tc & 0x0F))
 	d := h*uint64(time.Hour) + m*uint64(time.Minute) + s*uint64(time.Second) + f
 	t := Timecode(d & time_mask)
-	if tc&0x40 > 0 {
+	if tc&smpteDropFrame > 0 {
 		t |= df << time_bits
 	}
 	return t
@@ -240,55 +295,70 @@ func FromSMPTE(tc uint32, bits uint32) Timecode {
 // FromSMPTEwithRate unpacks the SMPTE timecode from tc, considering the
 // drop-frame bit and uses rate as initial timecode rate.
 func FromSMPTEwithRate(tc, bits uint32, rate float32) Timecode {
-	t := FromSMPTE(tc, bits)
-	if rate != 0 {
-		t.SetRate(NewFloatRate(rate))
+	if rate == 0 {
+		return FromSMPTE(tc, bits)
 	}
+	return FromSMPTEAtRate(tc, bits, NewFloatRate(rate))
+}
+
+// FromSMPTEAtRate unpacks the SMPTE timecode from tc at rate r. The
+// drop-frame bit selects between drop-frame and non-drop-frame variants
+// of r. High frame rate timecodes are unpacked according to ST 12-3. Use
+// FromSMPTEWithUserBits or FromSMPTEWithFlags to also read user bits and
+// flags.
+func FromSMPTEAtRate(tc, bits uint32, r Rate) Timecode {
+	t, _, _ := FromSMPTEWithFlags(tc, bits, r)
 	return t
 }
 
 // SMPTE returns a packed SMPTE timecode and user bits from the current
-// timecode value.
+// timecode value. Negative timecodes are packed by their absolute value.
+// The hours field can only hold 0..23, so addresses past 24h are wrapped
+// into the 24h address range, use Wrap() to obtain the number of midnight
+// rollovers. High frame rate
+// timecodes are packed as frame pairs according to ST 12-3. All user bits
+// and flags except the drop-frame flag are zero, use SMPTEWithUserBits or
+// SMPTEWithFlags to set them.
 func (t Timecode) SMPTE() (uint32, uint32) {
-	rate := t.Rate()
-	fps := int64(rate.fps)
-	frame := t.adjustedFrame(rate)
-	ff := frame % fps
-	ss := frame / fps % 60
-	mm := frame / (fps * 60) % 60
-	hh := frame / (fps * 3600)
-	tc := (hh/10)<<28 + hh%10<<24 + mm/10<<20 + mm%10<<16 + ss/10<<12 + ss%10<<8 + ff/10<<4 + ff%10
-	if rate.IsDrop() {
-		tc |= 0x40
-	}
-	return uint32(tc), 0
+	return t.SMPTEWithFlags(0, SMPTEFlags{})
 }
 
 // String returns a string representation of the timecode as `hh:mm:ss:ff`.
 // If the timecode uses a drop-frame edit rate, the last separator in the
-// string is a semicolon `;`.
+// string is a semicolon `;`. Negative timecodes are prefixed with `-`.
+// Hours are not limited to 24, use Wrap() to obtain a wall-clock address.
 func (t Timecode) String() string {
+	if t.IsNegative() {
+		return "-" + t.Abs().String()
+	}
 	rate := t.Rate()
-	frame := t.adjustedFrame(rate)
-	fps := int64(rate.fps)
-	ff := frame % fps
-	ss := frame / fps % 60
-	mm := frame / (fps * 60) % 60
-	hh := frame / (fps * 3600)
-	sep := ':'
-	if t.Rate().IsDrop() {
-		sep = ';'
+	return formatLabel(t.adjustedFrame(rate), rate)
+}
+
+// PairString returns the timecode as string `hh:mm:ss:ff.n` where ff counts
+// frame pairs (or quads) at the ST 12-3 base rate and n identifies the frame
+// within. Timecodes at rates up to 30 fps are returned like String().
+func (t Timecode) PairString() string {
+	if t.IsNegative() {
+		return "-" + t.Abs().PairString()
 	}
-	return fmt.Sprintf("%02d:%02d:%02d%c%02d", hh, mm, ss, sep, ff)
+	rate := t.Rate()
+	return formatPairLabel(t.adjustedFrame(rate), rate)
 }
 
 // StringWithRate returns the timecode as string appended with the current
-// rate after a separating `@` character.
+// rate after a separating `@` character. User-defined rates are written
+// as rational to keep them lossless.
 func (t Timecode) StringWithRate() string {
-	if t.Rate().enum == IdentityRate.enum {
+	r := t.Rate()
+	switch {
+	case r.enum == IdentityRate.enum:
 		return t.String()
+	case r.IsUserDefined():
+		return fmt.Sprintf("%s@%s", t.String(), r.RationalString())
+	default:
+		return fmt.Sprintf("%s@%s", t.String(), r.FloatString())
 	}
-	return fmt.Sprintf("%s@%s", t.String(), t.Rate().FloatString())
 }
 
 // Uint64 returns the raw timecode value as unsigned 64bit integer.
@@ -296,9 +366,10 @@ func (t Timecode) Uint64() uint64 {
 	return uint64(t)
 }
 
-// Duration returns the duration part of the timecode.
+// Duration returns the signed duration part of the timecode.
 func (t Timecode) Duration() time.Duration {
-	return time.Duration((uint64(t) & time_mask))
+	// sign-extend the 59bit duration value
+	return time.Duration(int64(uint64(t)<<rate_bits) >> rate_bits)
 }
 
 // Second returns a properly rounded number of seconds covered by the
@@ -315,8 +386,11 @@ func (t Timecode) Second() int64 {
 	//   60fps      16.666666ms
 	//  120fps      8.333333ms
 	//
-	// return int64(t.Duration() / time.Second)
-	return int64(math.Floor(float64(t.Duration())/float64(time.Second) + 0.001))
+	// negative timecodes are rounded like their absolute value
+	if t.IsNegative() {
+		return -t.Abs().Second()
+	}
+	return int64((t.Duration() + time.Millisecond) / time.Second)
 }
 
 // Second returns the number of milliseconds covered by the timecode.
@@ -329,31 +403,37 @@ func (t Timecode) Millisecond() int64 {
 // will be wrong when the edit rate is unknown or unset, as is the case
 // after parsing a timecode from string without setting the rate.
 func (t Timecode) Frame() int64 {
-	rate, ok := rates[int(uint64(t)>>time_bits)]
-	if !ok {
-		rate = rates[0]
-	}
-	return t.FrameAtRate(rate)
+	return t.FrameAtRate(t.Rate())
 }
 
 // FrameAtRate returns the frame sequence counter value corresponding to the
 // timecode's duration at edit rate r.
 func (t Timecode) FrameAtRate(r Rate) int64 {
+	if t.IsNegative() {
+		return -t.Abs().FrameAtRate(r)
+	}
+
 	// when rate id is 0 the frame number within the current second
 	// is stored as nanosecond value
 	if r.enum == 0 || r.enum == df {
-		f := int64(r.fps) * t.Second()
+		f := int64(r.fps) * int64(t.Duration()/time.Second)
 		f += int64(t.Duration() % time.Second)
 		return f
 	}
 
 	// all other cases use nanosecond as time base for duration
-	return int64(t.Duration() / r.FrameDuration())
+	return r.Frames(t.Duration())
 }
 
 func (t Timecode) adjustedFrame(r Rate) int64 {
-	f := t.FrameAtRate(r)
-	if !r.IsDrop() {
+	return frameToLabel(t.FrameAtRate(r), r)
+}
+
+// frameToLabel converts frame number f to a timecode address label counter
+// at rate r, i.e. the number of frames a non-drop-frame timecode would have
+// counted to display the same address.
+func frameToLabel(f int64, r Rate) int64 {
+	if !r.IsDrop() || r.dropFrames == 0 {
 		return f
 	}
 
@@ -366,30 +446,122 @@ func (t Timecode) adjustedFrame(r Rate) int64 {
 	return f + 9*df*d + df*((m-df)/int64(r.framesPer10Min/10))
 }
 
+// labelToFrame reverses frameToLabel. Labels that are skipped in drop-frame
+// mode resolve to the frame of the next valid label.
+func labelToFrame(l int64, r Rate) int64 {
+	if !r.IsDrop() || r.dropFrames == 0 {
+		return l
+	}
+	fpm := int64(r.fps) * 60
+	df := int64(r.dropFrames)
+	m := l / fpm
+	if m%10 != 0 && l%fpm < df {
+		l = m*fpm + df
+	}
+	return l - df*(m-m/10)
+}
+
+// parseLabel reads an address label of form 'hh:mm:ss:ff' and returns the
+// label counter at rate r.
+func parseLabel(s string, r Rate) (int64, error) {
+	var l int64
+	fps := int64(r.fps)
+	for i, v := range strings.Split(s, ":") {
+		t, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			// reject timecodes with invalid numbers
+			return 0, fmt.Errorf("timecode: parsing timecode \"%s\": invalid syntax", s)
+		}
+		switch i {
+		case 0:
+			l += int64(t) * 3600 * fps
+		case 1:
+			l += int64(t) * 60 * fps
+		case 2:
+			l += int64(t) * fps
+		case 3:
+			l += int64(t)
+		default:
+			// reject timecodes longer than 4 segements
+			return 0, fmt.Errorf("timecode: parsing timecode \"%s\": invalid syntax", s)
+		}
+	}
+	return l, nil
+}
+
+// formatLabel returns label counter l at rate r as string 'hh:mm:ss:ff'.
+func formatLabel(l int64, r Rate) string {
+	fps := int64(r.fps)
+	ff := l % fps
+	ss := l / fps % 60
+	mm := l / (fps * 60) % 60
+	hh := l / (fps * 3600)
+	sep := ':'
+	if r.IsDrop() {
+		sep = ';'
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%c%02d", hh, mm, ss, sep, ff)
+}
+
 // Sub returns the difference between timecodes t and t2 in nanoseconds as
 // time.Duration.
 func (t Timecode) Sub(t2 Timecode) time.Duration {
 	return t.Duration() - t2.Duration()
 }
 
+// Wrap returns timecode t wrapped into the 24h address range 00:00:00:00 to
+// 23:59:59:ff and the number of midnight rollovers it took to get there. The
+// rollover count is negative when t was negative.
+func (t Timecode) Wrap() (Timecode, int) {
+	r := t.Rate()
+	n := r.FramesPerDay()
+	f := t.Frame()
+	days := f / n
+	if f%n < 0 {
+		days--
+	}
+	if days == 0 {
+		return t, 0
+	}
+	w := New(0, r)
+	return w.SetFrame(f - days*n), int(days)
+}
+
+// SubWrap returns the forward distance from t2 to t in a 24h address range,
+// i.e. the time between both timecodes when t2 is before midnight and t is
+// after. The result is always positive and less than 24h of frames.
+func (t Timecode) SubWrap(t2 Timecode) time.Duration {
+	r := t.Rate()
+	n := r.FramesPerDay()
+	f := (t.Frame() - t2.FrameAtRate(r)) % n
+	if f < 0 {
+		f += n
+	}
+	return r.Duration(f)
+}
+
+// AddWrap works like Add but wraps the result at 24h. It returns the new
+// timecode and the number of midnight rollovers.
+func (t Timecode) AddWrap(d time.Duration) (Timecode, int) {
+	return t.Add(d).Wrap()
+}
+
+// AddFramesWrap works like AddFrames but wraps the result at 24h. It returns
+// the new timecode and the number of midnight rollovers.
+func (t Timecode) AddFramesWrap(f int64) (Timecode, int) {
+	return t.AddFrames(f).Wrap()
+}
+
 // Add returns a new timecode with current rate and duration d added to the
-// current duration. Any negative result will be clipped to zero.
+// current duration. The result may be negative.
 func (t Timecode) Add(d time.Duration) Timecode {
-	d = t.Duration() + d
-	if d < 0 {
-		return New(0, t.Rate())
-	}
-	return New(d, t.Rate())
+	return New(t.Duration()+d, t.Rate())
 }
 
 // AddFrames returns a new timecode adjusted by f frames relative to the
 // edit rate. If f is positive, the new timecode is larger than the
-// current one, if negative it will be smaller. Any negative result after
-// adding will be clipped to zero.
+// current one, if negative it will be smaller. The result may be negative.
 func (t Timecode) AddFrames(f int64) Timecode {
-	if f > t.Frame() {
-		return New(0, t.Rate())
-	}
 	return New(t.Duration()+t.Rate().Duration(f), t.Rate())
 }
 
@@ -417,7 +589,10 @@ func (t *Timecode) UnmarshalText(data []byte) error {
 
 // Scan implements sql.Scanner interface for converting database values
 // to timecode so you can use type timecode.Timecode directly with ORMs
-// or the sql package.
+// or the sql package. Strings are parsed as written by Value, an empty
+// string yields Invalid. Integer values as written by earlier versions keep
+// their rate only for standard rates because ids of user-defined rates
+// differ between processes.
 func (t *Timecode) Scan(value interface{}) error {
 	var x Timecode
 	var err error
@@ -425,9 +600,9 @@ func (t *Timecode) Scan(value interface{}) error {
 	case int64:
 		x = Timecode(v)
 	case string:
-		x, err = Parse(v)
+		x, err = scanString(v)
 	case []byte:
-		x, err = Parse(string(v))
+		x, err = scanString(string(v))
 	case nil:
 		x = Zero
 	}
@@ -438,18 +613,32 @@ func (t *Timecode) Scan(value interface{}) error {
 	return nil
 }
 
+func scanString(s string) (Timecode, error) {
+	if s == "" {
+		return Invalid, nil
+	}
+	return Parse(s)
+}
+
 // Value implements sql driver.Valuer interface for converting timecodes
-// to a database driver compatible type.
+// to a database driver compatible type. Timecodes are stored as string
+// with rate like MarshalText, so that user-defined rates survive without
+// relying on their process-local rate id. Invalid timecodes are stored
+// as empty string.
 func (t Timecode) Value() (driver.Value, error) {
-	return int64(t), nil
+	b, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
 }
 
 // ConvertTimecode implements schema.Converter function defined by the
 // Gorilla schema package. To use this converter you need to register it
 // via
 //
-//   dec := schema.NewDecoder()
-//   dec.RegisterConverter(timecode.Timecode(0), timecode.ConvertTimecode)
+//	dec := schema.NewDecoder()
+//	dec.RegisterConverter(timecode.Timecode(0), timecode.ConvertTimecode)
 //
 // This will eventually becomes unnecessary once https://github.com/gorilla/schema/issues/57
 // is fixed.
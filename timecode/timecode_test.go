@@ -291,3 +291,379 @@ func TestOffset(t *testing.T) {
 		v.Check(t, tt)
 	}
 }
+
+var (
+	UserRateTestcases []TimecodeTestcase = []TimecodeTestcase{
+		TimecodeTestcase{"12.5_1", 25, 2, s(1) + ms(360), 0, 1, 17, "00:00:01:04"},
+		TimecodeTestcase{"12.5_2", 25, 2, s(3600), 0, 3600, 45000, "00:57:41:07"},
+		TimecodeTestcase{"1000_1", 1000, 1, s(61) + ms(5), 0, 61, 61005, "00:01:01:05"},
+		TimecodeTestcase{"18_1", 18, 1, s(10), 0, 10, 180, "00:00:10:00"},
+	}
+)
+
+func TestUserRateRoundtrip(t *testing.T) {
+	for _, v := range UserRateTestcases {
+		r := NewRate(v.RateNum, v.RateDen)
+		tt := New(v.Time, r)
+		if tt.Rate() != r {
+			t.Errorf("[Case #%s] Rate not preserved: expected=%s got=%s", v.Id, r.RationalString(), tt.Rate().RationalString())
+		}
+		v.Check(t, tt)
+
+		// SMPTE
+		tc, bits := tt.SMPTE()
+		if x := FromSMPTE(tc, bits); x.SetRate(r) != tt {
+			t.Errorf("[Case #%s] SMPTE roundtrip failed: expected=%s got=%s", v.Id, tt, x)
+		}
+
+		// text
+		b, err := tt.MarshalText()
+		if err != nil {
+			t.Errorf("[Case #%s] MarshalText failed: %v", v.Id, err)
+		}
+		var x Timecode
+		if err := x.UnmarshalText(b); err != nil {
+			t.Errorf("[Case #%s] UnmarshalText failed: %v", v.Id, err)
+		}
+		if x != tt {
+			t.Errorf("[Case #%s] Text roundtrip failed: expected=%s got=%s", v.Id, tt.StringWithRate(), x.StringWithRate())
+		}
+
+		// sql
+		val, err := tt.Value()
+		if err != nil {
+			t.Errorf("[Case #%s] Value failed: %v", v.Id, err)
+		}
+		var y Timecode
+		if err := y.Scan(val); err != nil {
+			t.Errorf("[Case #%s] Scan failed: %v", v.Id, err)
+		}
+		if y != tt {
+			t.Errorf("[Case #%s] SQL roundtrip failed: expected=%s got=%s", v.Id, tt.StringWithRate(), y.StringWithRate())
+		}
+	}
+}
+
+func TestValueSelfDescribing(t *testing.T) {
+	tt := New(s(10), NewRate(25, 2))
+	val, err := tt.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	if _, ok := val.(string); !ok {
+		t.Fatalf("Wrong value type %T", val)
+	}
+	if v, _ := New(s(10), Rate25).Value(); v != "00:00:10:00@25.0" {
+		t.Errorf("Wrong value: expected=00:00:10:00@25.0 got=%v", v)
+	}
+
+	// scanning in a fresh process assigns a new rate id
+	userRatesMu.Lock()
+	saved := userRates
+	userRates = map[int]Rate{1: NewRate(1000, 7)}
+	userRatesMu.Unlock()
+	defer func() {
+		userRatesMu.Lock()
+		userRates = saved
+		userRatesMu.Unlock()
+	}()
+	var x Timecode
+	if err := x.Scan(val); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if x.Rate() != tt.Rate() || x.Frame() != tt.Frame() {
+		t.Errorf("Wrong timecode: expected=%s got=%s", tt.StringWithRate(), x.StringWithRate())
+	}
+
+	// invalid timecodes
+	if v, _ := Invalid.Value(); v != "" {
+		t.Errorf("Wrong value for invalid timecode: %v", v)
+	}
+	if err := x.Scan(""); err != nil || x.IsValid() {
+		t.Errorf("Wrong timecode for empty value: %s (%v)", x, err)
+	}
+}
+
+var (
+	TimecodeNegativeTestcases []TimecodeTestcase = []TimecodeTestcase{
+		TimecodeTestcase{"neg_24_1", 24, 1, -Rate24.Duration(36), 0, -1, -36, "-00:00:01:12"},
+		TimecodeTestcase{"neg_25_1", 25, 1, -s(3600) - ms(40), 0, -3600, -90001, "-01:00:00:01"},
+		TimecodeTestcase{"neg_29_1", 30000, 1001, -Rate30DF.Duration(1800), 0, -60, -1800, "-00:01:00;02"},
+	}
+)
+
+func TestNegative(t *testing.T) {
+	for _, v := range TimecodeNegativeTestcases {
+		r := NewRate(v.RateNum, v.RateDen)
+		tt := New(v.Time, r)
+		if !tt.IsNegative() {
+			t.Errorf("[Case #%s] Timecode should be negative", v.Id)
+		}
+		v.Check(t, tt)
+
+		// parse without and with rate
+		p, err := Parse(v.AsString)
+		if err != nil {
+			t.Errorf("[Case #%s] unexpected error: %v", v.Id, err)
+		}
+		v.Check(t, p.SetRate(r))
+		p, err = Parse(tt.StringWithRate())
+		if err != nil {
+			t.Errorf("[Case #%s] unexpected error: %v", v.Id, err)
+		}
+		v.Check(t, p)
+
+		// text and sql
+		b, _ := tt.MarshalText()
+		var x Timecode
+		if err := x.UnmarshalText(b); err != nil || x != tt {
+			t.Errorf("[Case #%s] Text roundtrip failed: expected=%s got=%s (%v)", v.Id, tt, x, err)
+		}
+		val, _ := tt.Value()
+		if err := x.Scan(val); err != nil || x != tt {
+			t.Errorf("[Case #%s] SQL roundtrip failed: expected=%s got=%s (%v)", v.Id, tt, x, err)
+		}
+
+		// arithmetic across zero
+		if a := tt.AddFrames(-v.Frame); !a.IsZero() {
+			t.Errorf("[Case #%s] Wrong zero crossing: expected=zero got=%s", v.Id, a)
+		}
+		if a := tt.Abs(); a.Frame() != -v.Frame || a.Compare(tt) != 1 || tt.Compare(a) != -1 {
+			t.Errorf("[Case #%s] Wrong absolute value %s", v.Id, a)
+		}
+		if d := tt.Sub(tt.Abs()); d != 2*tt.Duration() {
+			t.Errorf("[Case #%s] Wrong difference: expected=%d got=%d", v.Id, 2*tt.Duration(), d)
+		}
+	}
+}
+
+func TestParseNegativeInvalid(t *testing.T) {
+	for _, v := range []string{"-", "--00:00:01:00", "-00:-1:00:00"} {
+		if _, err := Parse(v); err == nil {
+			t.Errorf("Expected error parsing %q", v)
+		}
+	}
+}
+
+func TestAddBelowZero(t *testing.T) {
+	tt := New(Rate25.Duration(10), Rate25)
+	if x := tt.AddFrames(-35); x.Frame() != -25 || x.String() != "-00:00:01:00" {
+		t.Errorf("Wrong result: expected=-00:00:01:00 got=%s", x)
+	}
+	if x := tt.Add(-s(2)); x.Frame() != -40 {
+		t.Errorf("Wrong result: expected=-40 got=%d", x.Frame())
+	}
+	if x := New(0, Rate25).AddFrames(5); x.Frame() != 5 {
+		t.Errorf("Wrong result: expected=5 got=%d", x.Frame())
+	}
+}
+
+type TimecodeWrapTestcase struct {
+	Id        string
+	Rate      Rate
+	Frame     int64
+	Offset    int64
+	Rollovers int
+	AsString  string
+}
+
+var (
+	TimecodeWrapTestcases []TimecodeWrapTestcase = []TimecodeWrapTestcase{
+		TimecodeWrapTestcase{"25_1", Rate25, Rate25.FramesPerDay() - 1, 1, 1, "00:00:00:00"},
+		TimecodeWrapTestcase{"25_2", Rate25, Rate25.FramesPerDay() - 1, 0, 0, "23:59:59:24"},
+		TimecodeWrapTestcase{"25_3", Rate25, 0, -1, -1, "23:59:59:24"},
+		TimecodeWrapTestcase{"25_4", Rate25, 0, 3*Rate25.FramesPerDay() + 26, 3, "00:00:01:01"},
+		TimecodeWrapTestcase{"29_1", Rate30DF, Rate30DF.FramesPerDay() - 1, 0, 0, "23:59:59;29"},
+		TimecodeWrapTestcase{"29_2", Rate30DF, Rate30DF.FramesPerDay() - 1, 1, 1, "00:00:00;00"},
+		TimecodeWrapTestcase{"29_3", Rate30DF, 0, -1798, -1, "23:59:00;02"},
+		TimecodeWrapTestcase{"29_4", Rate30DF, 0, -1800, -1, "23:58:59;28"},
+		TimecodeWrapTestcase{"23_1", Rate23976, Rate23976.FramesPerDay() - 1, 25, 1, "00:00:01:00"},
+		TimecodeWrapTestcase{"59_1", Rate60DF, Rate60DF.FramesPerDay() - 1, 0, 0, "23:59:59;59"},
+	}
+)
+
+func TestWrap(t *testing.T) {
+	for _, v := range TimecodeWrapTestcases {
+		tt := New(v.Rate.Duration(v.Frame), v.Rate)
+		w, n := tt.AddFramesWrap(v.Offset)
+		if n != v.Rollovers {
+			t.Errorf("[Case #%s] Wrong rollovers: expected=%d got=%d", v.Id, v.Rollovers, n)
+		}
+		if s := w.String(); s != v.AsString {
+			t.Errorf("[Case #%s] Wrong string: expected=%s got=%s", v.Id, v.AsString, s)
+		}
+		w2, n2 := tt.AddWrap(v.Rate.Duration(v.Offset))
+		if w2 != w || n2 != n {
+			t.Errorf("[Case #%s] AddWrap mismatch: expected=%s/%d got=%s/%d", v.Id, w, n, w2, n2)
+		}
+		if d := w.SubWrap(tt); v.Rate.Frames(d) != (v.Offset%v.Rate.FramesPerDay()+v.Rate.FramesPerDay())%v.Rate.FramesPerDay() {
+			t.Errorf("[Case #%s] Wrong wrapped difference %s", v.Id, d)
+		}
+
+		// SMPTE always carries a wrapped address
+		if x := tt.AddFrames(v.Offset); !x.IsNegative() {
+			tc, _ := x.SMPTE()
+			if x := FromSMPTEAtRate(tc, 0, v.Rate); x != w {
+				t.Errorf("[Case #%s] Wrong SMPTE address: expected=%s got=%s", v.Id, w, x)
+			}
+		}
+	}
+}
+
+func TestSubWrapMidnight(t *testing.T) {
+	a, _ := Parse("23:59:50:00@25")
+	b, _ := Parse("00:00:05:00@25")
+	if d := b.SubWrap(a); d != s(15) {
+		t.Errorf("Wrong distance across midnight: expected=%s got=%s", s(15), d)
+	}
+	if d := a.SubWrap(b); d != 24*time.Hour-s(15) {
+		t.Errorf("Wrong distance: expected=%s got=%s", 24*time.Hour-s(15), d)
+	}
+}
+
+func TestWrapAddress(t *testing.T) {
+	a := New(s(25*3600), Rate25)
+	if x := a.String(); x != "25:00:00:00" {
+		t.Errorf("String must not wrap: expected=25:00:00:00 got=%s", x)
+	}
+	if w, n := a.Wrap(); w.String() != "01:00:00:00" || n != 1 {
+		t.Errorf("Wrong wrapped timecode: expected=01:00:00:00/1 got=%s/%d", w, n)
+	}
+	if x, _ := a.SMPTE(); x != 0x01000000 {
+		t.Errorf("Wrong SMPTE for timecode past 24h: expected=%08x got=%08x", 0x01000000, x)
+	}
+
+	// negative timecodes are packed by their absolute value
+	b := New(-s(3600), Rate25)
+	x, _ := b.SMPTE()
+	y, _ := b.Abs().SMPTE()
+	if x != y || x != 0x01000000 {
+		t.Errorf("Wrong SMPTE for negative timecode: expected=%08x got=%08x", 0x01000000, x)
+	}
+	w, _ := b.Wrap()
+	if x, _ := w.SMPTE(); x != 0x23000000 {
+		t.Errorf("Wrong SMPTE for wrapped timecode: expected=%08x got=%08x", 0x23000000, x)
+	}
+}
+
+func TestDropFrameLabels(t *testing.T) {
+	for _, r := range []Rate{Rate30DF, Rate60DF, Rate120DF} {
+		fps := int64(r.fps)
+		df := int64(r.dropFrames)
+		last := int64(-1)
+		for f := int64(0); f < r.FramesPerDay(); f++ {
+			l := frameToLabel(f, r)
+			step := int64(1)
+			if m := l / (fps * 60); l%(fps*60) == df && m%10 != 0 {
+				step = df + 1
+			}
+			if f > 0 && l-last != step {
+				t.Fatalf("[Rate %s] Wrong label step at frame %d: expected=%d got=%d", r.FloatString(), f, step, l-last)
+			}
+			if x := labelToFrame(l, r); x != f {
+				t.Fatalf("[Rate %s] Wrong frame for label %d: expected=%d got=%d", r.FloatString(), l, f, x)
+			}
+			last = l
+		}
+		if n := frameToLabel(r.FramesPerDay(), r); n != 24*3600*fps {
+			t.Errorf("[Rate %s] Wrong label count per day: expected=%d got=%d", r.FloatString(), 24*3600*fps, n)
+		}
+	}
+}
+
+type DropFrameLabelTestcase struct {
+	Rate     Rate
+	Frame    int64
+	AsString string
+}
+
+var (
+	DropFrameLabelTestcases []DropFrameLabelTestcase = []DropFrameLabelTestcase{
+		DropFrameLabelTestcase{Rate30DF, 17981, "00:09:59;29"},
+		DropFrameLabelTestcase{Rate30DF, 2589407, "23:59:59;29"},
+		DropFrameLabelTestcase{Rate60DF, 3600, "00:01:00;04"},
+		DropFrameLabelTestcase{Rate60DF, 35963, "00:09:59;59"},
+		DropFrameLabelTestcase{Rate120DF, 7199, "00:00:59;119"},
+		DropFrameLabelTestcase{Rate120DF, 7200, "00:01:00;08"},
+		DropFrameLabelTestcase{Rate120DF, 71928, "00:10:00;00"},
+		DropFrameLabelTestcase{Rate120DF, 71929, "00:10:00;01"},
+		DropFrameLabelTestcase{Rate120DF, 79128, "00:11:00;08"},
+	}
+)
+
+func TestDropFrameLabelStrings(t *testing.T) {
+	for _, v := range DropFrameLabelTestcases {
+		tc := New(v.Rate.Duration(v.Frame), v.Rate)
+		if s := tc.String(); s != v.AsString {
+			t.Errorf("[Case %s] Wrong string: expected=%s got=%s", v.AsString, v.AsString, s)
+		}
+		p, err := Parse(tc.StringWithRate())
+		if err != nil {
+			t.Errorf("[Case %s] unexpected error: %v", v.AsString, err)
+		}
+		if p != tc {
+			t.Errorf("[Case %s] Wrong parse result: expected=%s got=%s", v.AsString, tc.StringWithRate(), p.StringWithRate())
+		}
+		p, _ = Parse(v.AsString)
+		if p.SetRate(v.Rate) != tc {
+			t.Errorf("[Case %s] Wrong parse result after SetRate: expected=%d got=%d", v.AsString, v.Frame, p.Frame())
+		}
+	}
+
+	// skipped labels resolve to the next valid label
+	for _, v := range []string{"00:01:00;00@29.97", "00:01:00;01@29.97"} {
+		p, _ := Parse(v)
+		if s := p.String(); s != "00:01:00;02" {
+			t.Errorf("[Case %s] Wrong skipped label: expected=00:01:00;02 got=%s", v, s)
+		}
+	}
+}
+
+func TestParseSeparatorSelectsDropVariant(t *testing.T) {
+	for _, v := range []struct {
+		String string
+		Rate   Rate
+		Frame  int64
+	}{
+		{"01:00:00:00@29.97", Rate30DF.NonDrop(), 108000},
+		{"01:00:00;00@29.97", Rate30DF, 107892},
+		{"01:00:00:00@59.94", Rate5994, 216000},
+		{"01:00:00;00@59.94", Rate60DF, 215784},
+		{"01:00:00:00@119.88", Rate11988, 432000},
+		{"01:00:00;00@119.88", Rate120DF, 431568},
+		// an explicit suffix wins over the separator
+		{"01:00:00:00@29.97DF", Rate30DF, 107892},
+		{"01:00:00;00@29.97NDF", Rate2997, 108000},
+		{"01:00:00;00@59.94NDF", Rate5994, 216000},
+		{"01:00:00:00@59.94DF", Rate60DF, 215784},
+	} {
+		p, err := Parse(v.String)
+		if err != nil {
+			t.Errorf("[Case %s] unexpected error: %v", v.String, err)
+			continue
+		}
+		if r := p.Rate(); r != v.Rate {
+			t.Errorf("[Case %s] Wrong rate: expected=%s/%t got=%s/%t", v.String, v.Rate.RationalString(), v.Rate.IsDrop(), r.RationalString(), r.IsDrop())
+		}
+		if f := p.Frame(); f != v.Frame {
+			t.Errorf("[Case %s] Wrong frame: expected=%d got=%d", v.String, v.Frame, f)
+		}
+		if x, _ := Parse(p.StringWithRate()); x != p {
+			t.Errorf("[Case %s] Wrong roundtrip: %s", v.String, p.StringWithRate())
+		}
+	}
+}
+
+func TestNonDropFrameFractionalLabels(t *testing.T) {
+	for _, r := range []Rate{Rate23976, Rate47952, Rate5994, Rate11988} {
+		n := int64(r.fps) * 3600
+		tc := New(r.Duration(n), r)
+		if s := tc.String(); s != "01:00:00:00" {
+			t.Errorf("[Rate %s] Wrong string: expected=01:00:00:00 got=%s", r.FloatString(), s)
+		}
+		p, err := Parse(tc.StringWithRate())
+		if err != nil || p != tc {
+			t.Errorf("[Rate %s] Wrong parse result: expected=%s got=%s (%v)", r.FloatString(), tc.StringWithRate(), p.StringWithRate(), err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package tmcd implements QuickTime and MP4 timecode tracks.
+//
+// A timecode track has a 'tmcd' sample description that defines the
+// timecode rate as timescale and frame duration, and samples that hold
+// a 32 bit big-endian frame number counted from 00:00:00:00. The optional
+// 'name' atom inside the sample description usually carries the source
+// tape or reel name.
+package tmcd
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+// Flags are the timecode sample description flags.
+type Flags uint32
+
+const (
+	DropFrame       Flags = 0x0001
+	Max24Hour       Flags = 0x0002
+	NegativeTimesOK Flags = 0x0004
+	Counter         Flags = 0x0008
+)
+
+// SampleSize is the size of a timecode sample.
+const SampleSize = 4
+
+// size of the sample description without child atoms
+const descriptionSize = 34
+
+var (
+	ErrFormat   = errors.New("tmcd: invalid sample description")
+	ErrSample   = errors.New("tmcd: invalid sample")
+	ErrRate     = errors.New("tmcd: invalid rate")
+	ErrNegative = errors.New("tmcd: negative times not allowed")
+)
+
+// SampleDescription is a 'tmcd' timecode sample description.
+type SampleDescription struct {
+	DataReferenceIndex uint16
+	Flags              Flags
+	Timescale          uint32
+	FrameDuration      uint32
+	NumberOfFrames     uint8
+	// Name is the contents of the optional 'name' atom.
+	Name string
+}
+
+// NewSampleDescription creates a sample description for timecodes at rate
+// r. Like SMPTE timecodes, the timecode wraps at 24h.
+func NewSampleDescription(r timecode.Rate) SampleDescription {
+	num, den := r.Fraction()
+	d := SampleDescription{
+		DataReferenceIndex: 1,
+		Flags:              Max24Hour,
+		Timescale:          uint32(num),
+		FrameDuration:      uint32(den),
+		NumberOfFrames:     uint8(math.Round(float64(r.Float()))),
+	}
+	if r.IsDrop() {
+		d.Flags |= DropFrame
+	}
+	return d
+}
+
+// Rate returns the timecode rate. The drop-frame flag selects between
+// drop-frame and non-drop-frame variants of the rate.
+func (d SampleDescription) Rate() timecode.Rate {
+	if d.Timescale == 0 || d.FrameDuration == 0 {
+		return timecode.InvalidRate
+	}
+	r := timecode.NewRate(int(d.Timescale), int(d.FrameDuration))
+	if d.Flags&DropFrame > 0 {
+		r, _ = r.Drop()
+		return r
+	}
+	return r.NonDrop()
+}
+
+// MarshalBinary returns the sample description as 'tmcd' atom. The 'name'
+// atom is only included when Name is not empty.
+func (d SampleDescription) MarshalBinary() ([]byte, error) {
+	b := make([]byte, descriptionSize, descriptionSize+12+len(d.Name))
+	copy(b[4:], "tmcd")
+	binary.BigEndian.PutUint16(b[14:], d.DataReferenceIndex)
+	binary.BigEndian.PutUint32(b[20:], uint32(d.Flags))
+	binary.BigEndian.PutUint32(b[24:], d.Timescale)
+	binary.BigEndian.PutUint32(b[28:], d.FrameDuration)
+	b[32] = d.NumberOfFrames
+	if len(d.Name) > 0 {
+		if len(d.Name) > math.MaxUint16 {
+			return nil, ErrFormat
+		}
+		var name [12]byte
+		binary.BigEndian.PutUint32(name[:], uint32(12+len(d.Name)))
+		copy(name[4:], "name")
+		binary.BigEndian.PutUint16(name[8:], uint16(len(d.Name)))
+		b = append(append(b, name[:]...), d.Name...)
+	}
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+	return b, nil
+}
+
+// UnmarshalBinary reads a sample description from 'tmcd' atom data. Unknown
+// child atoms are ignored.
+func (d *SampleDescription) UnmarshalBinary(data []byte) error {
+	if len(data) < descriptionSize || string(data[4:8]) != "tmcd" {
+		return ErrFormat
+	}
+	size := binary.BigEndian.Uint32(data)
+	if size < descriptionSize || uint64(size) > uint64(len(data)) {
+		return ErrFormat
+	}
+	x := SampleDescription{
+		DataReferenceIndex: binary.BigEndian.Uint16(data[14:]),
+		Flags:              Flags(binary.BigEndian.Uint32(data[20:])),
+		Timescale:          binary.BigEndian.Uint32(data[24:]),
+		FrameDuration:      binary.BigEndian.Uint32(data[28:]),
+		NumberOfFrames:     data[32],
+	}
+	for atoms := data[descriptionSize:size]; len(atoms) > 0; {
+		if len(atoms) < 8 {
+			return ErrFormat
+		}
+		n := binary.BigEndian.Uint32(atoms)
+		if n < 8 || uint64(n) > uint64(len(atoms)) {
+			return ErrFormat
+		}
+		if string(atoms[4:8]) == "name" {
+			if n < 12 || 12+uint32(binary.BigEndian.Uint16(atoms[8:])) > n {
+				return ErrFormat
+			}
+			x.Name = string(atoms[12 : 12+binary.BigEndian.Uint16(atoms[8:])])
+		}
+		atoms = atoms[n:]
+	}
+	*d = x
+	return nil
+}
+
+// Sample returns the 4 byte sample for timecode t. Timecodes at a
+// different rate are converted to the frame at the description's rate.
+func (d SampleDescription) Sample(t timecode.Timecode) ([]byte, error) {
+	r := d.Rate()
+	if !r.IsValid() {
+		return nil, ErrRate
+	}
+	if d.Flags&Max24Hour > 0 {
+		t, _ = t.Wrap()
+	}
+	f := t.FrameAtRate(r)
+	if f < 0 && d.Flags&NegativeTimesOK == 0 {
+		return nil, ErrNegative
+	}
+	if f < math.MinInt32 || f > math.MaxInt32 {
+		return nil, ErrSample
+	}
+	b := make([]byte, SampleSize)
+	binary.BigEndian.PutUint32(b, uint32(int32(f)))
+	return b, nil
+}
+
+// Timecode returns the timecode stored in sample b.
+func (d SampleDescription) Timecode(b []byte) (timecode.Timecode, error) {
+	r := d.Rate()
+	if !r.IsValid() {
+		return timecode.Invalid, ErrRate
+	}
+	if err := timecode.RegisterRate(r); err != nil {
+		return timecode.Invalid, err
+	}
+	if len(b) != SampleSize {
+		return timecode.Invalid, ErrSample
+	}
+	f := int64(int32(binary.BigEndian.Uint32(b)))
+	if f < 0 && d.Flags&NegativeTimesOK == 0 {
+		return timecode.Invalid, ErrNegative
+	}
+	t := timecode.NewFrameCode(f, r).Timecode()
+	if d.Flags&Max24Hour > 0 {
+		t, _ = t.Wrap()
+	}
+	return t, nil
+}
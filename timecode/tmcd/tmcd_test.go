@@ -0,0 +1,167 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package tmcd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+type TmcdTestcase struct {
+	Name           string
+	Rate           timecode.Rate
+	Flags          Flags
+	Timescale      uint32
+	FrameDuration  uint32
+	NumberOfFrames uint8
+}
+
+var TmcdTestcases []TmcdTestcase = []TmcdTestcase{
+	{"23_976", timecode.Rate23976, Max24Hour, 24000, 1001, 24},
+	{"24", timecode.Rate24, Max24Hour, 24, 1, 24},
+	{"25", timecode.Rate25, Max24Hour, 25, 1, 25},
+	{"29_97DF", timecode.Rate30DF, Max24Hour | DropFrame, 30000, 1001, 30},
+	{"29_97NDF", timecode.Rate30DF.NonDrop(), Max24Hour, 30000, 1001, 30},
+	{"50", timecode.Rate50, Max24Hour, 50, 1, 50},
+	{"59_94DF", timecode.Rate60DF, Max24Hour | DropFrame, 60000, 1001, 60},
+	{"59_94NDF", timecode.Rate5994, Max24Hour, 60000, 1001, 60},
+}
+
+func TestSampleDescription(t *testing.T) {
+	for _, v := range TmcdTestcases {
+		d := NewSampleDescription(v.Rate)
+		if d.Flags != v.Flags || d.Timescale != v.Timescale || d.FrameDuration != v.FrameDuration || d.NumberOfFrames != v.NumberOfFrames {
+			t.Errorf("[Case #%s] Wrong sample description: %+v", v.Name, d)
+		}
+		if r := d.Rate(); r != v.Rate {
+			t.Errorf("[Case #%s] Wrong rate: expected=%s got=%s", v.Name, v.Rate.RationalString(), r.RationalString())
+		}
+		d.Name = "REEL_" + v.Name
+		b, err := d.MarshalBinary()
+		if err != nil {
+			t.Errorf("[Case #%s] MarshalBinary failed: %v", v.Name, err)
+		}
+		var x SampleDescription
+		if err := x.UnmarshalBinary(b); err != nil {
+			t.Errorf("[Case #%s] UnmarshalBinary failed: %v", v.Name, err)
+		}
+		if x != d {
+			t.Errorf("[Case #%s] Wrong sample description: expected=%+v got=%+v", v.Name, d, x)
+		}
+	}
+}
+
+func TestSampleDescriptionBinary(t *testing.T) {
+	d := NewSampleDescription(timecode.Rate30DF)
+	expected := []byte{
+		0x00, 0x00, 0x00, 0x22, 't', 'm', 'c', 'd',
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x03,
+		0x00, 0x00, 0x75, 0x30,
+		0x00, 0x00, 0x03, 0xE9,
+		0x1E, 0x00,
+	}
+	b, _ := d.MarshalBinary()
+	if !bytes.Equal(b, expected) {
+		t.Errorf("Wrong sample description: expected=% x got=% x", expected, b)
+	}
+	// a 2997/100 rate from older writers is the same rate
+	d.Timescale, d.FrameDuration = 2997, 100
+	if d.Rate() != timecode.Rate30DF {
+		t.Errorf("Wrong rate: expected=30000/1001 got=%s", d.Rate().RationalString())
+	}
+	var x SampleDescription
+	for i, b := range [][]byte{
+		expected[:33],
+		append([]byte{0, 0, 0, 0x22, 'x'}, expected[5:]...),
+		append([]byte{0, 0, 0, 0x23}, expected[4:]...),
+		append(append([]byte{0, 0, 0, 0x29}, expected[4:]...), 0, 0, 0, 7, 'a', 'b', 'c'),
+		append(append([]byte{0, 0, 0, 0x2E}, expected[4:]...), 0, 0, 0, 12, 'n', 'a', 'm', 'e', 0, 1, 0, 0),
+	} {
+		if err := x.UnmarshalBinary(b); err != ErrFormat {
+			t.Errorf("[Case #%d] Expected format error, got %v", i, err)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	for _, v := range TmcdTestcases {
+		d := NewSampleDescription(v.Rate)
+		tc := timecode.New(time.Hour, v.Rate)
+		b, err := d.Sample(tc)
+		if err != nil {
+			t.Errorf("[Case #%s] Sample failed: %v", v.Name, err)
+		}
+		x, err := d.Timecode(b)
+		if err != nil {
+			t.Errorf("[Case #%s] Timecode failed: %v", v.Name, err)
+		}
+		if x != tc {
+			t.Errorf("[Case #%s] Wrong timecode: expected=%s got=%s", v.Name, tc.StringWithRate(), x.StringWithRate())
+		}
+	}
+	d := NewSampleDescription(timecode.Rate30DF)
+	tc, _ := timecode.Parse("01:00:00;00@29.97")
+	if b, _ := d.Sample(tc); !bytes.Equal(b, []byte{0x00, 0x01, 0xA5, 0x74}) {
+		t.Errorf("Wrong sample: expected=00 01 a5 74 got=% x", b)
+	}
+	// timecodes at a different rate use the frame at the description's rate
+	if b, _ := d.Sample(timecode.New(time.Hour+time.Second, timecode.Rate25)); !bytes.Equal(b, []byte{0x00, 0x01, 0xA5, 0x92}) {
+		t.Errorf("Wrong sample: expected=00 01 a5 92 got=% x", b)
+	}
+}
+
+func TestSampleFlags(t *testing.T) {
+	d := NewSampleDescription(timecode.Rate25)
+	tc := timecode.New(-time.Second, timecode.Rate25)
+	// wraps to 23:59:59:00
+	b, err := d.Sample(tc)
+	if err != nil || !bytes.Equal(b, []byte{0x00, 0x20, 0xF5, 0x67}) {
+		t.Errorf("Wrong wrapped sample: % x (%v)", b, err)
+	}
+	d.Flags = 0
+	if _, err := d.Sample(tc); err != ErrNegative {
+		t.Errorf("Expected negative error, got %v", err)
+	}
+	if _, err := d.Timecode([]byte{0xFF, 0xFF, 0xFF, 0xE7}); err != ErrNegative {
+		t.Errorf("Expected negative error, got %v", err)
+	}
+	d.Flags = NegativeTimesOK
+	b, err = d.Sample(tc)
+	if err != nil || !bytes.Equal(b, []byte{0xFF, 0xFF, 0xFF, 0xE7}) {
+		t.Errorf("Wrong negative sample: % x (%v)", b, err)
+	}
+	if x, _ := d.Timecode(b); x != tc {
+		t.Errorf("Wrong negative timecode: expected=%s got=%s", tc, x)
+	}
+	// without 24h wrap frame numbers beyond 24h are kept
+	x, _ := d.Timecode([]byte{0x00, 0x20, 0xF5, 0x80})
+	if x.String() != "24:00:00:00" {
+		t.Errorf("Wrong timecode: expected=24:00:00:00 got=%s", x)
+	}
+	if _, err := d.Timecode(b[:3]); err != ErrSample {
+		t.Errorf("Expected sample error, got %v", err)
+	}
+	if _, err := (SampleDescription{}).Timecode(b); err != ErrRate {
+		t.Errorf("Expected rate error, got %v", err)
+	}
+	if _, err := (SampleDescription{}).Sample(tc); err != ErrRate {
+		t.Errorf("Expected rate error, got %v", err)
+	}
+}
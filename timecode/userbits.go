@@ -0,0 +1,130 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// SMPTE ST 12-1-2014, binary groups
+// SMPTE ST 262-1995, binary groups character sets
+// SMPTE ST 309-1999, date and time zone in binary groups
+
+package timecode
+
+import (
+	"strings"
+)
+
+// UserBits holds the 8 binary groups (user bits) of a SMPTE ST 12-1 timecode
+// in the same layout as the user bits word used by FromSMPTE and SMPTE.
+// Binary group 1 occupies the 4 least significant bits.
+type UserBits uint32
+
+// UserBitsFormat is the 3 bit value of the binary group flags BGF2, BGF1 and
+// BGF0 that identifies how user bits are encoded.
+type UserBitsFormat uint8
+
+const (
+	UserBitsUnspecified UserBitsFormat = 0 // user-defined, character set not specified
+	UserBitsChars       UserBitsFormat = 1 // 8-bit characters (ISO/IEC 646 or 2022)
+	UserBitsDate        UserBitsFormat = 2 // date and time zone (ST 309)
+	UserBitsPageLine    UserBitsFormat = 5 // page/line multiplex (ST 262)
+)
+
+// BGF0, BGF1 and BGF2 return the individual binary group flags.
+func (f UserBitsFormat) BGF0() bool { return f&1 > 0 }
+func (f UserBitsFormat) BGF1() bool { return f&2 > 0 }
+func (f UserBitsFormat) BGF2() bool { return f&4 > 0 }
+
+// Group returns binary group n in range 1..8.
+func (u UserBits) Group(n int) uint8 {
+	if n < 1 || n > 8 {
+		return 0
+	}
+	return uint8(u >> (4 * uint(n-1)) & 0x0F)
+}
+
+// SetGroup returns user bits with binary group n in range 1..8 set to the
+// 4 least significant bits of v.
+func (u UserBits) SetGroup(n int, v uint8) UserBits {
+	if n < 1 || n > 8 {
+		return u
+	}
+	shift := 4 * uint(n-1)
+	return u&^(0x0F<<shift) | UserBits(v&0x0F)<<shift
+}
+
+// CharUserBits packs up to 4 8-bit characters from s into user bits. Each
+// character occupies two consecutive binary groups starting with its least
+// significant bits. Longer strings are truncated.
+func CharUserBits(s string) UserBits {
+	var u UserBits
+	for i := 0; i < len(s) && i < 4; i++ {
+		u |= UserBits(s[i]) << (8 * uint(i))
+	}
+	return u
+}
+
+// Chars returns the user bits as string of 8-bit characters. Trailing NUL
+// characters are removed.
+func (u UserBits) Chars() string {
+	b := []byte{byte(u), byte(u >> 8), byte(u >> 16), byte(u >> 24)}
+	return strings.TrimRight(string(b), "\x00")
+}
+
+// DateUserBits packs a ST 309 date in YYMMDD format together with time zone
+// code zone into user bits. Only the last 2 digits of year are stored. The
+// time zone code is stored as is, see ST 309 for the list of valid codes.
+func DateUserBits(year, month, day int, zone uint8) UserBits {
+	var u UserBits
+	year %= 100
+	for i, v := range []int{day % 10, day / 10, month % 10, month / 10, year % 10, year / 10} {
+		u = u.SetGroup(i+1, uint8(v))
+	}
+	u = u.SetGroup(7, zone&0x0F)
+	u = u.SetGroup(8, zone>>4&0x03)
+	return u
+}
+
+// Date returns the ST 309 date and time zone code from user bits. Two-digit
+// years below 70 are mapped to 20xx, other years to 19xx.
+func (u UserBits) Date() (year, month, day int, zone uint8) {
+	day = int(u.Group(2))*10 + int(u.Group(1))
+	month = int(u.Group(4))*10 + int(u.Group(3))
+	year = int(u.Group(6))*10 + int(u.Group(5))
+	if year < 70 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	zone = u.Group(8)&0x03<<4 | u.Group(7)
+	return
+}
+
+// UserBitsFromSMPTE returns the user bits and the user bits format stored in
+// the binary group flags of packed SMPTE timecode tc at rate r. The rate is
+// required because flag positions differ between 25 and 30 fps timecodes.
+func UserBitsFromSMPTE(tc, bits uint32, r Rate) (UserBits, UserBitsFormat) {
+	return UserBits(bits), FlagsFromSMPTE(tc, r).BGF
+}
+
+// SMPTEWithUserBits works like SMPTE but also returns user bits u and sets
+// the binary group flags for format f.
+func (t Timecode) SMPTEWithUserBits(u UserBits, f UserBitsFormat) (uint32, uint32) {
+	return t.SMPTEWithFlags(u, SMPTEFlags{BGF: f})
+}
+
+// FromSMPTEWithUserBits unpacks the SMPTE timecode from tc at rate r like
+// FromSMPTEAtRate and also returns the user bits and user bits format. It
+// reverses SMPTEWithUserBits.
+func FromSMPTEWithUserBits(tc, bits uint32, r Rate) (Timecode, UserBits, UserBitsFormat) {
+	t, u, f := FromSMPTEWithFlags(tc, bits, r)
+	return t, u, f.BGF
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package timecode
+
+import (
+	"testing"
+)
+
+func TestUserBitsGroups(t *testing.T) {
+	var u UserBits
+	for i := 1; i <= 8; i++ {
+		u = u.SetGroup(i, uint8(i))
+	}
+	if u != 0x87654321 {
+		t.Errorf("Wrong user bits: expected=%08x got=%08x", 0x87654321, uint32(u))
+	}
+	for i := 1; i <= 8; i++ {
+		if g := u.Group(i); g != uint8(i) {
+			t.Errorf("Wrong binary group %d: expected=%d got=%d", i, i, g)
+		}
+	}
+	if u.SetGroup(9, 1) != u || u.Group(0) != 0 {
+		t.Errorf("Out of range binary groups should be ignored")
+	}
+}
+
+func TestUserBitsChars(t *testing.T) {
+	for _, v := range []string{"", "A", "REEL", "TAPE"} {
+		if s := CharUserBits(v).Chars(); s != v {
+			t.Errorf("Wrong characters: expected=%q got=%q", v, s)
+		}
+	}
+	if u := CharUserBits("AB"); u.Group(1) != 0x1 || u.Group(2) != 0x4 || u.Group(3) != 0x2 || u.Group(4) != 0x4 {
+		t.Errorf("Wrong character layout: %08x", uint32(u))
+	}
+	if s := CharUserBits("REEL01").Chars(); s != "REEL" {
+		t.Errorf("Wrong truncated characters: expected=REEL got=%q", s)
+	}
+}
+
+func TestUserBitsDate(t *testing.T) {
+	u := DateUserBits(2017, 12, 31, 0x25)
+	if u != 0x25171231 {
+		t.Errorf("Wrong date user bits: expected=%08x got=%08x", 0x25171231, uint32(u))
+	}
+	y, m, d, z := u.Date()
+	if y != 2017 || m != 12 || d != 31 || z != 0x25 {
+		t.Errorf("Wrong date: got=%d-%d-%d zone=%02x", y, m, d, z)
+	}
+	if y, _, _, _ := DateUserBits(1999, 1, 1, 0).Date(); y != 1999 {
+		t.Errorf("Wrong year: expected=1999 got=%d", y)
+	}
+}
+
+func TestUserBitsSMPTE(t *testing.T) {
+	for _, r := range []Rate{Rate25, Rate30DF, Rate50, Rate60, Rate24} {
+		tc := New(r.Duration(1234), r)
+		for f := UserBitsFormat(0); f < 8; f++ {
+			u := CharUserBits("TC01")
+			x, bits := tc.SMPTEWithUserBits(u, f)
+			if y := FromSMPTEAtRate(x, bits, r); y != tc {
+				t.Errorf("[Rate %s] Flags %d changed timecode: expected=%s got=%s", r.FloatString(), f, tc, y)
+			}
+			u2, f2 := UserBitsFromSMPTE(x, bits, r)
+			if u2 != u || f2 != f {
+				t.Errorf("[Rate %s] Wrong user bits: expected=%08x/%d got=%08x/%d", r.FloatString(), uint32(u), f, uint32(u2), f2)
+			}
+		}
+	}
+
+	// flag positions differ between 25 and 30 fps
+	tc, _ := Zero.SMPTEWithUserBits(0, UserBitsChars)
+	if x, _ := New(0, Rate25).SMPTEWithUserBits(0, UserBitsChars); x != 0x8000 {
+		t.Errorf("Wrong BGF0 position at 25 fps: expected=%08x got=%08x", 0x8000, x)
+	}
+	if x, _ := New(0, Rate30).SMPTEWithUserBits(0, UserBitsChars); x != 0x800000 || tc != x {
+		t.Errorf("Wrong BGF0 position at 30 fps: expected=%08x got=%08x", 0x800000, x)
+	}
+	if x, _ := New(0, Rate25).SMPTEWithUserBits(0, UserBitsPageLine); x != 0x808000 {
+		t.Errorf("Wrong BGF2 position at 25 fps: expected=%08x got=%08x", 0x808000, x)
+	}
+	if x, _ := New(0, Rate30).SMPTEWithUserBits(0, UserBitsDate); x != 0x40000000 {
+		t.Errorf("Wrong BGF1 position at 30 fps: expected=%08x got=%08x", 0x40000000, x)
+	}
+}
+
+func TestFromSMPTEWithUserBits(t *testing.T) {
+	for _, r := range []Rate{Rate25, Rate30DF, Rate2997, Rate50, Rate120DF} {
+		tc := New(r.Duration(4321), r)
+		u := DateUserBits(2017, 5, 4, 0x21)
+		x, bits := tc.SMPTEWithUserBits(u, UserBitsDate)
+		if bits != uint32(u) {
+			t.Errorf("[Rate %s] Wrong user bits word: expected=%08x got=%08x", r.FloatString(), uint32(u), bits)
+		}
+		y, u2, f := FromSMPTEWithUserBits(x, bits, r)
+		if y != tc || u2 != u || f != UserBitsDate {
+			t.Errorf("[Rate %s] Wrong result: expected=%s/%08x/%d got=%s/%08x/%d", r.FloatString(), tc, uint32(u), UserBitsDate, y, uint32(u2), f)
+		}
+		flags := SMPTEFlags{DropFrame: r.IsDrop(), BGF: UserBitsDate}
+		if _, _, f := FromSMPTEWithFlags(x, bits, r); f != flags {
+			t.Errorf("[Rate %s] Wrong flags: expected=%+v got=%+v", r.FloatString(), flags, f)
+		}
+
+		// SMPTE packs like SMPTEWithFlags without user bits and flags
+		a, b := tc.SMPTE()
+		if c, d := tc.SMPTEWithFlags(0, SMPTEFlags{}); a != c || b != d {
+			t.Errorf("[Rate %s] Wrong SMPTE: expected=%08x/%08x got=%08x/%08x", r.FloatString(), c, d, a, b)
+		}
+	}
+}
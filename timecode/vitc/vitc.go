@@ -0,0 +1,231 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package vitc implements SMPTE ST 12-1 vertical interval timecode (VITC).
+//
+// A VITC codeword has 90 bits in 9 groups of 10 bits. Each group starts
+// with the sync bit pair 1 0 followed by 8 bits of payload. Groups 1-8
+// carry the packed timecode and user bits in the same nibble order as LTC,
+// group 9 carries an 8 bit CRC with generator polynomial x^8+1 over all
+// preceding bits including sync bits. Bits are transmitted LSB first.
+//
+// VITC is inserted into a line of each field in the vertical blanking
+// interval. The field mark flag identifies the second field of a frame.
+package vitc
+
+import (
+	"errors"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+// Bits is the number of bits in a VITC codeword.
+const Bits = 90
+
+var (
+	ErrSync     = errors.New("vitc: invalid sync bits")
+	ErrCRC      = errors.New("vitc: CRC mismatch")
+	ErrNoSignal = errors.New("vitc: no signal")
+)
+
+// Word is a 90 bit VITC codeword. Bit n is stored in w[n/8] at bit n%8
+// which is the order bits are transmitted in.
+type Word [12]byte
+
+// NewWord returns the VITC codeword for packed SMPTE timecode tc and user
+// bits including sync bits and CRC.
+func NewWord(tc, bits uint32) Word {
+	var w Word
+	data := timecode.InterleaveSMPTE(tc, bits)
+	for g := 0; g < 9; g++ {
+		w.set(10*g, true)
+		if g == 8 {
+			break
+		}
+		for i := 0; i < 8; i++ {
+			w.set(10*g+2+i, data&(1<<uint(8*g+i)) > 0)
+		}
+	}
+	crc := w.CRC()
+	for i := 0; i < 8; i++ {
+		w.set(82+i, crc&(1<<uint(i)) > 0)
+	}
+	return w
+}
+
+// Bit returns codeword bit n.
+func (w Word) Bit(n int) bool {
+	return w[n/8]&(1<<uint(n%8)) > 0
+}
+
+func (w *Word) set(n int, v bool) {
+	if v {
+		w[n/8] |= 1 << uint(n%8)
+	} else {
+		w[n/8] &^= 1 << uint(n%8)
+	}
+}
+
+// CRC computes the CRC over codeword bits 0-81. Bit 0 of the result is
+// the first transmitted CRC bit.
+//
+// Division by x^8+1 folds the message into 8 bit columns, so every CRC
+// bit is the exclusive or of all preceding bits at the same position
+// modulo 8.
+func (w Word) CRC() uint8 {
+	var crc uint8
+	for n := 0; n < 82; n++ {
+		if w.Bit(n) {
+			crc ^= 1 << uint((n+6)%8)
+		}
+	}
+	return crc
+}
+
+// Validate checks the codeword's sync bits and CRC.
+func (w Word) Validate() error {
+	for g := 0; g < 9; g++ {
+		if !w.Bit(10*g) || w.Bit(10*g+1) {
+			return ErrSync
+		}
+	}
+	var crc uint8
+	for i := 0; i < 8; i++ {
+		if w.Bit(82 + i) {
+			crc |= 1 << uint(i)
+		}
+	}
+	if crc != w.CRC() {
+		return ErrCRC
+	}
+	return nil
+}
+
+// SMPTE returns the packed SMPTE timecode and user bits from the codeword.
+// The codeword is not validated.
+func (w Word) SMPTE() (uint32, uint32) {
+	var data uint64
+	for g := 0; g < 8; g++ {
+		for i := 0; i < 8; i++ {
+			if w.Bit(10*g + 2 + i) {
+				data |= 1 << uint(8*g+i)
+			}
+		}
+	}
+	return timecode.DeinterleaveSMPTE(data)
+}
+
+// Frame validates the codeword and returns its contents as frame at rate r.
+func (w Word) Frame(r timecode.Rate) (Frame, error) {
+	if err := w.Validate(); err != nil {
+		return Frame{}, err
+	}
+	tc, bits := w.SMPTE()
+	t, u, f := timecode.FromSMPTEWithFlags(tc, bits, r)
+	return Frame{
+		Timecode: t,
+		UserBits: u,
+		Flags:    f,
+	}, nil
+}
+
+// String returns the codeword bits in transmission order.
+func (w Word) String() string {
+	b := make([]byte, Bits)
+	for i := range b {
+		b[i] = '0'
+		if w.Bit(i) {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+// Frame is the contents of a single VITC codeword.
+type Frame struct {
+	Timecode timecode.Timecode
+	UserBits timecode.UserBits
+	// Flags holds the codeword's flag bits. The field mark is set in VITC
+	// of the second field.
+	Flags timecode.SMPTEFlags
+}
+
+// Word returns the frame's VITC codeword.
+func (f Frame) Word() Word {
+	return NewWord(f.Timecode.SMPTEWithFlags(f.UserBits, f.Flags))
+}
+
+// DecodeLine slices a VITC codeword from a line of 8 bit luma samples with
+// a bit length of bitLen samples. The slicing level is halfway between the
+// line's minimum and maximum level and bits are sampled at their center.
+// Sampling is resynchronized at the falling edge within each sync bit pair.
+//
+// At 13.5 MHz sampling bitLen is 7.448 for 625 line (116 bits per line
+// period) and 7.461 for 525 line (115 bits per line period) systems.
+func DecodeLine(line []byte, bitLen float64) (Word, error) {
+	var w Word
+	if len(line) == 0 || bitLen < 2 {
+		return w, ErrNoSignal
+	}
+	lo, hi := line[0], line[0]
+	for _, v := range line {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	if hi-lo < 32 {
+		return w, ErrNoSignal
+	}
+	thr := float64(lo) + float64(hi-lo)/2
+	pos := edge(line, thr, 1, len(line), true)
+	if pos < 0 {
+		return w, ErrNoSignal
+	}
+	for g := 0; g < 9; g++ {
+		for b := 0; b < 10; b++ {
+			x := int(pos + (float64(b)+0.5)*bitLen)
+			if x >= len(line) {
+				return w, ErrSync
+			}
+			w.set(10*g+b, float64(line[x]) >= thr)
+		}
+		next := pos + 11*bitLen
+		if p := edge(line, thr, int(next-bitLen/2), int(next+bitLen/2)+1, false); p >= 0 {
+			next = p
+		}
+		pos = next - bitLen
+	}
+	return w, w.Validate()
+}
+
+// edge returns the interpolated position of the first rising or falling
+// edge through level thr in line[from:to], or -1.
+func edge(line []byte, thr float64, from, to int, rising bool) float64 {
+	if from < 1 {
+		from = 1
+	}
+	if to > len(line) {
+		to = len(line)
+	}
+	for i := from; i < to; i++ {
+		a, b := float64(line[i-1]), float64(line[i])
+		if (a < thr && b >= thr && rising) || (a >= thr && b < thr && !rising) {
+			return float64(i-1) + (thr-a)/(b-a)
+		}
+	}
+	return -1
+}
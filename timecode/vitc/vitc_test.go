@@ -0,0 +1,143 @@
+// Copyright (c) 2017 Alexander Eichhorn
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package vitc
+
+import (
+	"testing"
+
+	"github.com/trimmer-io/go-timecode/timecode"
+)
+
+type VITCTestcase struct {
+	Name     string
+	Rate     timecode.Rate
+	Timecode string
+	UserBits timecode.UserBits
+	Flags    timecode.SMPTEFlags
+	BitLen   float64
+}
+
+var VITCTestcases []VITCTestcase = []VITCTestcase{
+	{"25", timecode.Rate25, "10:11:12:13", 0, timecode.SMPTEFlags{}, 7.448},
+	{"25_field2", timecode.Rate25, "23:59:59:24", 0xFFFFFFFF, timecode.SMPTEFlags{FieldMark: true}, 7.448},
+	{"29_97DF", timecode.Rate30DF, "01:23:45;29", timecode.CharUserBits("TAPE"), timecode.SMPTEFlags{FieldMark: true, BGF: timecode.UserBitsChars}, 7.461},
+	{"29_97NDF", timecode.Rate30DF, "00:01:00:00", 0, timecode.SMPTEFlags{}, 7.461},
+	{"30", timecode.Rate30, "00:00:00:00", 0x12345678, timecode.SMPTEFlags{ColorFrame: true}, 7.461},
+}
+
+func (v VITCTestcase) Frame(t *testing.T) Frame {
+	tc, err := timecode.Parse(v.Timecode + "@" + v.Rate.FloatString())
+	if err != nil {
+		t.Fatalf("[Case #%s] Parse failed: %v", v.Name, err)
+	}
+	return Frame{tc, v.UserBits, v.Flags}
+}
+
+// line renders codeword w into a line of luma samples with VITC starting
+// at sample 20 and smoothed transitions.
+func line(w Word, bitLen float64) []byte {
+	l := make([]byte, 720)
+	for i := range l {
+		l[i] = 16
+		n := int((float64(i) - 20) / bitLen)
+		if i >= 20 && n < Bits && w.Bit(n) {
+			l[i] = 192
+		}
+	}
+	for i := len(l) - 1; i > 0; i-- {
+		l[i] = byte((int(l[i]) + int(l[i-1])) / 2)
+	}
+	return l
+}
+
+func TestWord(t *testing.T) {
+	for _, v := range VITCTestcases {
+		f := v.Frame(t)
+		w := f.Word()
+		if err := w.Validate(); err != nil {
+			t.Errorf("[Case #%s] Validate failed: %v", v.Name, err)
+		}
+		s := w.String()
+		for g := 0; g < 9; g++ {
+			if s[10*g:10*g+2] != "10" {
+				t.Errorf("[Case #%s] Wrong sync bits in group %d: %s", v.Name, g+1, s[10*g:10*g+2])
+			}
+		}
+		// every column modulo 8 including the CRC adds up to zero
+		for c := 0; c < 8; c++ {
+			x := false
+			for n := c; n < Bits; n += 8 {
+				x = x != w.Bit(n)
+			}
+			if x {
+				t.Errorf("[Case #%s] Wrong CRC parity in column %d", v.Name, c)
+			}
+		}
+		x, err := w.Frame(v.Rate)
+		if err != nil {
+			t.Errorf("[Case #%s] Frame failed: %v", v.Name, err)
+		}
+		if x.Timecode != f.Timecode || x.UserBits != f.UserBits {
+			t.Errorf("[Case #%s] Wrong frame: expected=%s/%08x got=%s/%08x", v.Name, f.Timecode, uint32(f.UserBits), x.Timecode, uint32(x.UserBits))
+		}
+		if x.Flags.FieldMark != v.Flags.FieldMark || x.Flags.ColorFrame != v.Flags.ColorFrame || x.Flags.BGF != v.Flags.BGF {
+			t.Errorf("[Case #%s] Wrong flags: expected=%+v got=%+v", v.Name, v.Flags, x.Flags)
+		}
+	}
+}
+
+func TestWordErrors(t *testing.T) {
+	tc := timecode.New(0, timecode.Rate25)
+	w := Frame{Timecode: tc}.Word()
+	for n := 0; n < Bits; n++ {
+		x := w
+		x.set(n, !x.Bit(n))
+		expected := ErrCRC
+		if n%10 < 2 {
+			expected = ErrSync
+		}
+		if err := x.Validate(); err != expected {
+			t.Errorf("Bit %d flipped: expected error %v, got %v", n, expected, err)
+		}
+		if _, err := x.Frame(timecode.Rate25); err != expected {
+			t.Errorf("Bit %d flipped: expected error %v, got %v", n, expected, err)
+		}
+	}
+}
+
+func TestDecodeLine(t *testing.T) {
+	for _, v := range VITCTestcases {
+		w := v.Frame(t).Word()
+		x, err := DecodeLine(line(w, v.BitLen), v.BitLen)
+		if err != nil {
+			t.Errorf("[Case #%s] DecodeLine failed: %v", v.Name, err)
+		}
+		if x != w {
+			t.Errorf("[Case #%s] Wrong codeword: expected=%s got=%s", v.Name, w, x)
+		}
+		// sampling clock mismatch
+		x, err = DecodeLine(line(w, v.BitLen*1.02), v.BitLen)
+		if err != nil || x != w {
+			t.Errorf("[Case #%s] Wrong codeword with clock mismatch: %v", v.Name, err)
+		}
+	}
+	if _, err := DecodeLine(make([]byte, 720), 7.5); err != ErrNoSignal {
+		t.Errorf("Expected no signal error, got %v", err)
+	}
+	w := Frame{Timecode: timecode.New(0, timecode.Rate25)}.Word()
+	if _, err := DecodeLine(line(w, 7.5)[:400], 7.5); err != ErrSync {
+		t.Errorf("Expected sync error on truncated line, got %v", err)
+	}
+}